@@ -0,0 +1,75 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"github.com/mjourard/aoc-2019/intcode"
+	"io"
+	"log"
+	"os"
+)
+
+func main() {
+	//read in the program and the optional script of input lines to replay before handing over to the terminal
+	if len(os.Args) < 2 {
+		log.Fatalln("Usage: <exe> <input_file_of_intcode_program> [script_of_input_lines] [replay]")
+	}
+	program, err := intcode.LoadIntCodeProgram(os.Args[1])
+	if err != nil {
+		log.Fatalln(err)
+	}
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	//lines typed at the terminal are already on screen, only the scripted ones are echoed
+	in := []intcode.InputSource{}
+	if len(os.Args) > 2 {
+		script, err := os.Open(os.Args[2])
+		if err != nil {
+			log.Fatalln(err)
+		}
+		defer script.Close()
+		in = append(in, intcode.ASCIIInput(script, out))
+	}
+	//a replay only plays the script, so that the same script always produces the same transcript
+	if len(os.Args) < 4 || os.Args[3] != "replay" {
+		in = append(in, intcode.ASCIIInput(os.Stdin, nil))
+	}
+
+	machine := intcode.InitIO(program, &sources{in: in, out: out}, intcode.ASCIIOutput(out))
+	_, err = machine.Run()
+	if errors.Is(err, intcode.ErrInputExhausted) {
+		out.Flush()
+		log.Fatalln("the program wanted more input than there was")
+	}
+	if err != nil {
+		out.Flush()
+		log.Fatalln(err)
+	}
+}
+
+//sources reads from each input source in turn, flushing the output before every read so prompts are shown before the
+//program waits on the terminal
+type sources struct {
+	in  []intcode.InputSource
+	out *bufio.Writer
+}
+
+func (s *sources) ReadInt() (int, error) {
+	if err := s.out.Flush(); err != nil {
+		return 0, err
+	}
+	for len(s.in) > 0 {
+		val, err := s.in[0].ReadInt()
+		if err != io.EOF {
+			return val, err
+		}
+		s.in = s.in[1:]
+	}
+	return 0, io.EOF
+}
@@ -0,0 +1,39 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package main
+
+import (
+	"fmt"
+	"github.com/mjourard/aoc-2019/intcode"
+	"io/ioutil"
+	"log"
+	"os"
+)
+
+func main() {
+	//read in the file that contains the assembly source
+	if len(os.Args) < 2 {
+		log.Fatalln("Usage: <exe> <input_file_of_intcode_assembly> [output_file_of_intcode_program]")
+	}
+	src, err := os.Open(os.Args[1])
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer src.Close()
+	program, err := intcode.Assemble(src)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	tape := intcode.FormatProgram(program) + "\n"
+	if len(os.Args) < 3 {
+		fmt.Print(tape)
+		return
+	}
+	if err := ioutil.WriteFile(os.Args[2], []byte(tape), 0644); err != nil {
+		log.Fatalln(err)
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package main
+
+import (
+	"github.com/mjourard/aoc-2019/intcode"
+	"log"
+	"os"
+)
+
+func main() {
+	//read in the program and where to write the graph
+	if len(os.Args) < 2 {
+		log.Fatalln("Usage: <exe> <input_file_of_intcode_program> [output_dot_file]")
+	}
+	program, err := intcode.LoadIntCodeProgram(os.Args[1])
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	out := os.Stdout
+	if len(os.Args) > 2 {
+		out, err = os.Create(os.Args[2])
+		if err != nil {
+			log.Fatalln(err)
+		}
+		defer out.Close()
+	}
+	if err := intcode.BuildCFG(program).WriteDOT(out); err != nil {
+		log.Fatalln(err)
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package main
+
+import (
+	"github.com/mjourard/aoc-2019/intcode"
+	"log"
+	"os"
+)
+
+func main() {
+	//read in the program, the names to compile it to and where to write the source
+	if len(os.Args) < 4 {
+		log.Fatalln("Usage: <exe> <input_file_of_intcode_program> <package_name> <function_name> [output_go_file]")
+	}
+	program, err := intcode.LoadIntCodeProgram(os.Args[1])
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	out := os.Stdout
+	if len(os.Args) > 4 {
+		out, err = os.Create(os.Args[4])
+		if err != nil {
+			log.Fatalln(err)
+		}
+		defer out.Close()
+	}
+	if err := intcode.Compile(out, program, os.Args[2], os.Args[3]); err != nil {
+		log.Fatalln(err)
+	}
+}
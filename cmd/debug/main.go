@@ -0,0 +1,39 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package main
+
+import (
+	"github.com/mjourard/aoc-2019/intcode"
+	"log"
+	"os"
+)
+
+func main() {
+	//read in the file that contains the program
+	if len(os.Args) < 2 {
+		log.Fatalln("Usage: <exe> <input_file_of_intcode_program> [input_to_program]")
+	}
+	program, err := intcode.LoadIntCodeProgram(os.Args[1])
+	if err != nil {
+		log.Fatalln(err)
+	}
+	//without an input file the program's input is queued from the debugger with the input command
+	var in intcode.InputSource
+	if len(os.Args) > 2 {
+		file, err := os.Open(os.Args[2])
+		if err != nil {
+			log.Fatalln(err)
+		}
+		defer file.Close()
+		in = intcode.TextInput(file)
+	}
+	machine := intcode.InitIO(program, in, nil)
+
+	debugger := intcode.NewDebugger(machine, os.Stdout)
+	if err := debugger.Repl(os.Stdin); err != nil {
+		log.Fatalln(err)
+	}
+}
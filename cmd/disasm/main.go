@@ -0,0 +1,27 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package main
+
+import (
+	"fmt"
+	"github.com/mjourard/aoc-2019/intcode"
+	"log"
+	"os"
+)
+
+func main() {
+	//read in the file that contains the program
+	if len(os.Args) < 2 {
+		log.Fatalln("Usage: <exe> <input_file_of_intcode_program>")
+	}
+	program, err := intcode.LoadIntCodeProgram(os.Args[1])
+	if err != nil {
+		log.Fatalln(err)
+	}
+	for _, in := range intcode.Disassemble(program) {
+		fmt.Println(in)
+	}
+}
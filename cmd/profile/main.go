@@ -0,0 +1,55 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package main
+
+import (
+	"github.com/mjourard/aoc-2019/intcode"
+	"io/ioutil"
+	"log"
+	"os"
+)
+
+//reportTop is the number of entries listed in each section of the report
+const reportTop = 20
+
+func main() {
+	//read in the program and its input
+	if len(os.Args) < 3 {
+		log.Fatalln("Usage: <exe> <input_file_of_intcode_program> <input_to_program> [output_pprof_file]")
+	}
+	program, err := intcode.LoadIntCodeProgram(os.Args[1])
+	if err != nil {
+		log.Fatalln(err)
+	}
+	in, err := os.Open(os.Args[2])
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer in.Close()
+
+	//the program's own output is dropped so it doesn't get mixed in with the report
+	machine := intcode.Init(program, in, ioutil.Discard)
+	profile := intcode.NewProfile()
+	machine.SetProfile(profile)
+	if _, err := machine.Run(); err != nil {
+		log.Fatalln(err)
+	}
+
+	if err := profile.WriteReport(os.Stdout, machine, reportTop); err != nil {
+		log.Fatalln(err)
+	}
+	if len(os.Args) < 4 {
+		return
+	}
+	pprofFile, err := os.Create(os.Args[3])
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer pprofFile.Close()
+	if err := profile.WritePprof(pprofFile, machine); err != nil {
+		log.Fatalln(err)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package main
+
+import (
+	"github.com/mjourard/aoc-2019/intcode"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+//imageScale is the number of pixels wide and high each tile is drawn in images
+const imageScale = 8
+
+//frameDelay is how long each frame of an animated GIF is shown for, in hundredths of a second
+const frameDelay = 4
+
+func main() {
+	//read in the program, what to draw its output with and where to write the image of it
+	if len(os.Args) < 2 {
+		log.Fatalln("Usage: <exe> <input_file_of_intcode_program> [arcade|paint] [input_to_program] [image.png|image.gif]")
+	}
+	program, err := intcode.LoadIntCodeProgram(os.Args[1])
+	if err != nil {
+		log.Fatalln(err)
+	}
+	palette := intcode.ArcadePalette
+	if len(os.Args) > 2 {
+		switch os.Args[2] {
+		case "arcade":
+		case "paint":
+			palette = intcode.PaintPalette
+		default:
+			log.Fatalf("unknown palette '%s', expected arcade or paint\n", os.Args[2])
+		}
+	}
+	screen := intcode.NewScreen(palette)
+
+	//without any input the program runs until it first asks for some
+	var in intcode.InputSource
+	if len(os.Args) > 3 {
+		f, err := os.Open(os.Args[3])
+		if err != nil {
+			log.Fatalln(err)
+		}
+		defer f.Close()
+		in = &frameInput{in: intcode.TextInput(f), screen: screen}
+	}
+	machine := intcode.InitIO(program, in, screen)
+	if _, err := machine.Run(); err != nil {
+		log.Fatalln(err)
+	}
+	if err := screen.Render(os.Stdout); err != nil {
+		log.Fatalln(err)
+	}
+	if len(os.Args) < 5 {
+		return
+	}
+
+	out, err := os.Create(os.Args[4])
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer out.Close()
+	switch filepath.Ext(os.Args[4]) {
+	case ".png":
+		err = screen.WritePNG(out, imageScale)
+	case ".gif":
+		err = screen.WriteGIF(out, imageScale, frameDelay)
+	default:
+		log.Fatalf("unknown image type '%s', expected .png or .gif\n", filepath.Ext(os.Args[4]))
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+//frameInput captures a frame of the screen every time the program reads input, which is once per tick of a game
+type frameInput struct {
+	in     intcode.InputSource
+	screen *intcode.Screen
+}
+
+func (f *frameInput) ReadInt() (int, error) {
+	f.screen.CaptureFrame()
+	return f.in.ReadInt()
+}
@@ -0,0 +1,60 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package main
+
+import (
+	"bufio"
+	"github.com/mjourard/aoc-2019/intcode"
+	"log"
+	"os"
+)
+
+func main() {
+	//read in the program, its input and where to write the trace
+	if len(os.Args) < 4 {
+		log.Fatalln("Usage: <exe> <input_file_of_intcode_program> <input_to_program> <output_trace_file> [json|binary]")
+	}
+	program, err := intcode.LoadIntCodeProgram(os.Args[1])
+	if err != nil {
+		log.Fatalln(err)
+	}
+	in, err := os.Open(os.Args[2])
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer in.Close()
+	traceFile, err := os.Create(os.Args[3])
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer traceFile.Close()
+	w := bufio.NewWriter(traceFile)
+
+	format := "json"
+	if len(os.Args) > 4 {
+		format = os.Args[4]
+	}
+	var tracer intcode.Tracer
+	switch format {
+	case "json":
+		tracer = intcode.JSONTracer(w)
+	case "binary":
+		tracer = intcode.BinaryTracer(w)
+	default:
+		log.Fatalf("unknown trace format '%s', expected json or binary\n", format)
+	}
+
+	machine := intcode.Init(program, in, os.Stdout)
+	machine.SetTracer(tracer)
+	_, runErr := machine.Run()
+	//flush whatever was traced even if the program failed, the trace is most useful then
+	if err := w.Flush(); err != nil {
+		log.Fatalln(err)
+	}
+	if runErr != nil {
+		log.Fatalln(runErr)
+	}
+}
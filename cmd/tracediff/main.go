@@ -0,0 +1,47 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package main
+
+import (
+	"fmt"
+	"github.com/mjourard/aoc-2019/intcode"
+	"log"
+	"os"
+)
+
+func main() {
+	//read in the two traces to compare
+	if len(os.Args) < 3 {
+		log.Fatalln("Usage: <exe> <trace_file_a> <trace_file_b>")
+	}
+	a, err := openTrace(os.Args[1])
+	if err != nil {
+		log.Fatalln(err)
+	}
+	b, err := openTrace(os.Args[2])
+	if err != nil {
+		log.Fatalln(err)
+	}
+	divergence, err := intcode.DiffTraces(a, b)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if divergence == nil {
+		fmt.Println("traces are identical")
+		return
+	}
+	fmt.Println(divergence)
+	os.Exit(1)
+}
+
+func openTrace(filename string) (intcode.TraceReader, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	//the file is read until the process exits
+	return intcode.ReadTrace(file)
+}
@@ -6,111 +6,172 @@
 package main
 
 import (
-	"encoding/csv"
-	"errors"
+	"context"
+	"flag"
 	"fmt"
-	"io"
+	"github.com/mjourard/aoc-2019/intcode"
 	"log"
-	"os"
-	"strconv"
+	"runtime"
+	"sync"
 )
 
 const TargetOutput = 19690720
 
 func main() {
+	target := flag.Int("target", TargetOutput, "value that must be left at position 0")
+	min := flag.Int("min", 0, "smallest noun and verb to try")
+	max := flag.Int("max", 99, "largest noun and verb to try")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of programs to run at once")
+	quiet := flag.Bool("quiet", false, "only print the answer")
+	solve := flag.Bool("solve", false, "solve for the noun and verb symbolically instead of trying every pair")
+	flag.Parse()
+
 	//read in the file that contains the input
-	if len(os.Args) < 2 {
-		panic("Usage: <exe> <input_file_of_masses>")
+	if flag.NArg() < 1 {
+		log.Fatalln("Usage: <exe> [-target n] [-min n] [-max n] [-workers n] [-quiet] [-solve] <input_file_of_intcode_program>")
+	}
+	if *min > *max {
+		log.Fatalf("min %d is larger than max %d\n", *min, *max)
 	}
-	program, err := LoadIntCodeProgram(os.Args[1])
+	if *workers < 1 {
+		*workers = 1
+	}
+	program, err := intcode.LoadIntCodeProgram(flag.Arg(0))
 	if err != nil {
 		log.Fatalln(err)
 	}
-	var i, j int
-	found := false
-
-	for i = 0; i <= 99; i++ {
-		for j = 0; j <= 99; j++ {
-			//clone the program
-			clone := make([]int, len(program))
-			copy(clone, program)
-			//run the program with the noun and verb combination
-			out, err := RunIntCodeProgram(clone, i, j)
-			if err != nil {
-				log.Fatalln(err)
-			}
-			fmt.Printf("n: %d, v: %d = %d\n", i, j, out)
-			if out == TargetOutput {
-				fmt.Println("Found target output!! Halting...")
-				found = true
-				break
-			}
+
+	if *solve {
+		noun, verb := solveNounVerb(program, *target, *min, *max, *quiet)
+		if *quiet {
+			fmt.Println(100*noun + verb)
+			return
 		}
-		if found {
-			break
+		fmt.Printf("The noun %d and the verb %d produce %d\nThe final value of 100 * noun + verb = %d\n", noun, verb, *target, 100*noun+verb)
+		return
+	}
+
+	report := func(noun int, verb int, out int) {}
+	if !*quiet {
+		var mu sync.Mutex
+		report = func(noun int, verb int, out int) {
+			mu.Lock()
+			defer mu.Unlock()
+			fmt.Printf("n: %d, v: %d = %d\n", noun, verb, out)
 		}
 	}
 
-	fmt.Printf("The noun %d and the verb %d produce %d\nThe final value of 100 * noun + verb = %d\n", i, j, TargetOutput, 100*i+j)
+	//every noun and verb combination runs from a fork of the same loaded machine, so the program is only copied once
+	base := intcode.Init(program, nil, nil)
+	noun, verb, found, err := Search(context.Background(), base, *target, *min, *max, *workers, report)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if !found {
+		log.Fatalf("no noun and verb between %d and %d produce %d\n", *min, *max, *target)
+	}
+	if *quiet {
+		fmt.Println(100*noun + verb)
+		return
+	}
+	fmt.Printf("The noun %d and the verb %d produce %d\nThe final value of 100 * noun + verb = %d\n", noun, verb, *target, 100*noun+verb)
 }
 
-func LoadIntCodeProgram(filename string) ([]int, error) {
-	csvfile, err := os.Open(filename)
+//solveNounVerb finds the noun and verb that produce target with intcode.Solve, exiting when there are none
+func solveNounVerb(program []int, target int, min int, max int, quiet bool) (noun int, verb int) {
+	solution, err := intcode.Solve(program, 0, target, []intcode.Unknown{{Addr: 1, Min: min, Max: max}, {Addr: 2, Min: min, Max: max}})
 	if err != nil {
-		return nil, err
+		log.Fatalln(err)
+	}
+	if solution == nil {
+		log.Fatalf("no noun and verb between %d and %d produce %d\n", min, max, target)
 	}
+	if !quiet {
+		if solution.Searched {
+			fmt.Println("The program branches on the noun or verb, every pair was searched")
+		} else {
+			coefs, constant, _ := solution.Expr.Linear()
+			fmt.Printf("Position 0 holds %d * noun + %d * verb + %d\n", coefs[1], coefs[2], constant)
+		}
+	}
+	return solution.Values[1], solution.Values[2]
+}
 
-	// Parse the file
-	r := csv.NewReader(csvfile)
+//Search tries every noun and verb between min and max inclusive across a pool of workers and returns the first pair
+//found that leaves target at position 0. The remaining work is cancelled as soon as a pair is found, one of the
+//programs fails or ctx is done. report, if not nil, is called with the result of every program that finished; it is
+//called from the workers, so it must be safe to call concurrently
+func Search(ctx context.Context, base *intcode.Intcode, target int, min int, max int, workers int,
+	report func(noun int, verb int, out int)) (noun int, verb int, found bool, err error) {
+	parent := ctx
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
 
-	// Iterate through the records
-	// Read each record from csv
-	tape, err := r.Read()
-	if err == io.EOF {
-		return nil, errors.New("input file did not contain an Intcode program")
+	type pair struct{ noun, verb int }
+	pairs := make(chan pair)
+	var once sync.Once
+	var wg sync.WaitGroup
+	//finish records the first answer or error and stops everything else
+	finish := func(p pair, runErr error) {
+		once.Do(func() {
+			noun, verb, found, err = p.noun, p.verb, runErr == nil, runErr
+			cancel()
+		})
 	}
-	if err != nil {
-		return nil, err
+
+	for w := 0; w < workers; w++ {
+		//each worker forks its own copy of the base here, before the goroutines start, so that forking base is
+		//never done concurrently
+		workerBase := base.Fork()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range pairs {
+				out, runErr := RunNounVerb(workerBase, p.noun, p.verb)
+				if runErr != nil {
+					finish(p, fmt.Errorf("noun %d, verb %d: %v", p.noun, p.verb, runErr))
+					return
+				}
+				if report != nil {
+					report(p.noun, p.verb, out)
+				}
+				if out == target {
+					finish(p, nil)
+					return
+				}
+			}
+		}()
 	}
-	intcodes := make([]int, 0)
-	for _, opcode := range tape {
-		curInt, err := strconv.Atoi(opcode)
-		if err != nil {
-			return nil, errors.New(fmt.Sprintf("unable to convert opcode '%s' to a number", opcode))
+
+feed:
+	for n := min; n <= max; n++ {
+		for v := min; v <= max; v++ {
+			select {
+			case pairs <- pair{n, v}:
+			case <-ctx.Done():
+				break feed
+			}
 		}
-		intcodes = append(intcodes, curInt)
 	}
-	return intcodes, nil
-}
+	close(pairs)
+	wg.Wait()
 
-func RunIntCodeProgram(program []int, noun int, verb int) (int, error) {
-	//load the noun and verb
-	program[1] = noun
-	program[2] = verb
-
-	//run the program
-	pos := 0
-	var opcode, loc1, loc2, loc3 int
-	for {
-		if pos >= len(program) {
-			return -1, errors.New("ended up at a position that is greater than the length of the passed in program")
-		}
-		opcode = program[pos]
-		//immediately halt on opcode 99
-		if opcode == 99 {
-			break
-		}
-		loc1 = program[pos+1]
-		loc2 = program[pos+2]
-		loc3 = program[pos+3]
-		switch opcode {
-		case 1:
-			program[loc3] = program[loc1] + program[loc2]
-		case 2:
-			program[loc3] = program[loc1] * program[loc2]
-		}
-		pos += 4
+	if !found && err == nil {
+		//nil unless the search was cut short by the caller rather than by an answer or a failed program
+		err = parent.Err()
 	}
+	return noun, verb, found, err
+}
+
+//RunNounVerb loads the noun and verb into a fork of base, runs it and returns the value left at position 0. base itself
+//is left unmodified
+func RunNounVerb(base *intcode.Intcode, noun int, verb int) (int, error) {
+	machine := base.Fork()
+	machine.Poke(1, noun)
+	machine.Poke(2, verb)
 
-	return program[0], nil
+	if _, err := machine.Run(); err != nil {
+		return -1, err
+	}
+	return machine.Peek(0), nil
 }
@@ -0,0 +1,74 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mjourard/aoc-2019/intcode"
+)
+
+//addProgram leaves noun + verb at position 0
+var addProgram = []int{1101, 0, 0, 0, 99}
+
+func TestSearch(t *testing.T) {
+	type args struct {
+		target  int
+		min     int
+		max     int
+		workers int
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantFound bool
+	}{
+		{
+			name:      "found_single_worker",
+			args:      args{target: 150, min: 0, max: 99, workers: 1},
+			wantFound: true,
+		},
+		{
+			name:      "found_many_workers",
+			args:      args{target: 7, min: 0, max: 99, workers: 8},
+			wantFound: true,
+		},
+		{
+			name:      "out_of_range",
+			args:      args{target: 150, min: 0, max: 50, workers: 4},
+			wantFound: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := intcode.Init(addProgram, nil, nil)
+			noun, verb, found, err := Search(context.Background(), base, tt.args.target, tt.args.min, tt.args.max,
+				tt.args.workers, nil)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if found != tt.wantFound {
+				t.Fatalf("Search() found = %v, want %v", found, tt.wantFound)
+			}
+			if found && noun+verb != tt.args.target {
+				t.Errorf("Search() = %d, %d which sum to %d, want %d", noun, verb, noun+verb, tt.args.target)
+			}
+			if got := base.Peek(0); got != 1101 {
+				t.Errorf("base Peek(0) = %d, Search() modified the base machine", got)
+			}
+		})
+	}
+}
+
+func TestSearch_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, found, err := Search(ctx, intcode.Init(addProgram, nil, nil), -1, 0, 99, 4, nil)
+	if found || err != context.Canceled {
+		t.Errorf("Search() found = %v, err = %v, want false, %v", found, err, context.Canceled)
+	}
+}
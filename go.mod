@@ -0,0 +1,5 @@
+module github.com/mjourard/aoc-2019
+
+go 1.18
+
+require github.com/pkg/errors v0.9.1
@@ -0,0 +1,203 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package intcode
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"sync"
+)
+
+//Topology describes how the machines of a pipeline are wired together
+type Topology int
+
+const (
+	//Chain connects each machine's output to the next machine's input. The last machine's output is the signal
+	Chain Topology = iota
+	//FeedbackLoop is a Chain where the last machine's output is also fed back into the first machine's input. The
+	//signal is the last value output by the last machine once every machine has halted
+	FeedbackLoop
+)
+
+//errPipelineAborted is returned to the machines still running in a pipeline once another machine in it has failed
+var errPipelineAborted = errors.New("pipeline aborted")
+
+//RunPipeline runs one copy of program per phase setting, each in its own goroutine, connected by channels in the order
+//of the phase settings. Every machine reads its phase setting as its first input and the first machine then reads the
+//input signal. It returns the last value output by the last machine
+func RunPipeline(program []int, phases []int, topology Topology, signal int) (int, error) {
+	if len(phases) == 0 {
+		return 0, errors.New("a pipeline needs at least one phase setting")
+	}
+	done := make(chan struct{})
+	var abort sync.Once
+
+	//channels[k] feeds machine k. Each one is buffered so a phase setting and a pending signal never block the writer
+	channels := make([]chan int, len(phases))
+	//stopped[k] is closed once machine k exits, so anything still writing to it doesn't block forever
+	stopped := make([]chan struct{}, len(phases))
+	for k, phase := range phases {
+		channels[k] = make(chan int, 2)
+		channels[k] <- phase
+		stopped[k] = make(chan struct{})
+	}
+	channels[0] <- signal
+
+	closed := make(chan struct{})
+	close(closed)
+
+	last := &signalOutput{}
+	if topology == FeedbackLoop {
+		last.next = &pipeOutput{ch: channels[0], stopped: stopped[0], done: done}
+	}
+
+	errs := make([]error, len(phases))
+	var wg sync.WaitGroup
+	for k := range phases {
+		var out OutputSink = last
+		if k < len(phases)-1 {
+			out = &pipeOutput{ch: channels[k+1], stopped: stopped[k+1], done: done}
+		}
+		//the machine feeding the first one is the last one in a feedback loop, and no one at all in a chain
+		writer := closed
+		if k > 0 {
+			writer = stopped[k-1]
+		} else if topology == FeedbackLoop {
+			writer = stopped[len(phases)-1]
+		}
+		machine := InitIO(program, &pipeInput{ch: channels[k], writerStopped: writer, done: done}, out)
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			defer close(stopped[k])
+			if _, err := machine.Run(); err != nil {
+				errs[k] = err
+				abort.Do(func() { close(done) })
+			}
+		}(k)
+	}
+	wg.Wait()
+
+	for k, err := range errs {
+		if err != nil && errors.Cause(err) != errPipelineAborted {
+			return 0, errors.Wrap(err, fmt.Sprintf("machine %d with phase setting %d failed", k, phases[k]))
+		}
+	}
+	if !last.written {
+		return 0, errors.New("the last machine in the pipeline never produced a signal")
+	}
+	return last.val, nil
+}
+
+//MaxSignal runs the pipeline for every ordering of the passed in phase settings and returns the highest signal along
+//with the ordering that produced it. The pipeline is started with an input signal of 0
+func MaxSignal(program []int, phases []int, topology Topology) (int, []int, error) {
+	var best int
+	var bestPhases []int
+	var err error
+	Permutations(phases, func(perm []int) bool {
+		var signal int
+		signal, err = RunPipeline(program, perm, topology, 0)
+		if err != nil {
+			return false
+		}
+		if bestPhases == nil || signal > best {
+			best = signal
+			bestPhases = append([]int(nil), perm...)
+		}
+		return true
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return best, bestPhases, nil
+}
+
+//Permutations calls visit with every ordering of vals, using Heap's algorithm. The slice passed to visit is reused
+//between calls, so it must be copied to be kept. Returning false from visit stops the search
+func Permutations(vals []int, visit func([]int) bool) {
+	perm := append([]int(nil), vals...)
+	counters := make([]int, len(perm))
+	if !visit(perm) {
+		return
+	}
+	for k := 1; k < len(perm); {
+		if counters[k] >= k {
+			counters[k] = 0
+			k++
+			continue
+		}
+		if k%2 == 0 {
+			perm[0], perm[k] = perm[k], perm[0]
+		} else {
+			perm[counters[k]], perm[k] = perm[k], perm[counters[k]]
+		}
+		if !visit(perm) {
+			return
+		}
+		counters[k]++
+		k = 1
+	}
+}
+
+//pipeInput reads from a pipeline channel until the pipeline is aborted or the machine writing to it has stopped
+type pipeInput struct {
+	ch            <-chan int
+	writerStopped <-chan struct{}
+	done          <-chan struct{}
+}
+
+func (p *pipeInput) ReadInt() (int, error) {
+	select {
+	case val := <-p.ch:
+		return val, nil
+	case <-p.writerStopped:
+		//anything already buffered can still be read, but nothing new will ever arrive
+		select {
+		case val := <-p.ch:
+			return val, nil
+		default:
+			return 0, errors.New("the machine feeding this one has stopped, no more input will arrive")
+		}
+	case <-p.done:
+		return 0, errPipelineAborted
+	}
+}
+
+//pipeOutput writes to a pipeline channel until the pipeline is aborted. Values written after the reading machine has
+//stopped are dropped
+type pipeOutput struct {
+	ch      chan<- int
+	stopped <-chan struct{}
+	done    <-chan struct{}
+}
+
+func (p *pipeOutput) WriteInt(val int) error {
+	select {
+	case p.ch <- val:
+		return nil
+	case <-p.stopped:
+		return nil
+	case <-p.done:
+		return errPipelineAborted
+	}
+}
+
+//signalOutput records the last value output by the last machine of a pipeline before passing it on to next, if any
+type signalOutput struct {
+	next    OutputSink
+	val     int
+	written bool
+}
+
+func (s *signalOutput) WriteInt(val int) error {
+	s.val = val
+	s.written = true
+	if s.next == nil {
+		return nil
+	}
+	return s.next.WriteInt(val)
+}
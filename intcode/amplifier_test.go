@@ -0,0 +1,93 @@
+package intcode
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMaxSignal(t *testing.T) {
+	tests := []struct {
+		name       string
+		program    []int
+		phases     []int
+		topology   Topology
+		want       int
+		wantPhases []int
+	}{
+		{
+			name:       "d7_p1_example1",
+			program:    []int{3, 15, 3, 16, 1002, 16, 10, 16, 1, 16, 15, 15, 4, 15, 99, 0, 0},
+			phases:     []int{0, 1, 2, 3, 4},
+			topology:   Chain,
+			want:       43210,
+			wantPhases: []int{4, 3, 2, 1, 0},
+		},
+		{
+			name: "d7_p1_example2",
+			program: []int{3, 23, 3, 24, 1002, 24, 10, 24, 1002, 23, -1, 23,
+				101, 5, 23, 23, 1, 24, 23, 23, 4, 23, 99, 0, 0},
+			phases:     []int{0, 1, 2, 3, 4},
+			topology:   Chain,
+			want:       54321,
+			wantPhases: []int{0, 1, 2, 3, 4},
+		},
+		{
+			name: "d7_p2_example1",
+			program: []int{3, 26, 1001, 26, -4, 26, 3, 27, 1002, 27, 2, 27, 1, 27, 26,
+				27, 4, 27, 1001, 28, -1, 28, 1005, 28, 6, 99, 0, 0, 5},
+			phases:     []int{5, 6, 7, 8, 9},
+			topology:   FeedbackLoop,
+			want:       139629729,
+			wantPhases: []int{9, 8, 7, 6, 5},
+		},
+		{
+			name: "d7_p2_example2",
+			program: []int{3, 52, 1001, 52, -5, 52, 3, 53, 1, 52, 56, 54, 1007, 54, 5, 55, 1005, 55, 26, 1001, 54,
+				-5, 54, 1105, 1, 12, 1, 53, 54, 53, 1008, 54, 0, 55, 1001, 55, 1, 55, 2, 53, 55, 53, 4,
+				53, 1001, 56, -1, 56, 1005, 56, 6, 99, 0, 0, 0, 0, 10},
+			phases:     []int{5, 6, 7, 8, 9},
+			topology:   FeedbackLoop,
+			want:       18216,
+			wantPhases: []int{9, 7, 8, 5, 6},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotPhases, err := MaxSignal(tt.program, tt.phases, tt.topology)
+			if err != nil {
+				t.Fatalf("MaxSignal() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("MaxSignal() signal = %d, want %d", got, tt.want)
+			}
+			if !reflect.DeepEqual(gotPhases, tt.wantPhases) {
+				t.Errorf("MaxSignal() phases = %v, want %v", gotPhases, tt.wantPhases)
+			}
+		})
+	}
+}
+
+func TestRunPipeline_MachineFails(t *testing.T) {
+	//reads one more input than the pipeline will ever give it
+	program := []int{3, 9, 3, 9, 3, 9, 4, 9, 99, 0}
+	if _, err := RunPipeline(program, []int{0}, Chain, 0); err == nil {
+		t.Errorf("RunPipeline() chain error = nil, want an error when the input runs out")
+	}
+	if _, err := RunPipeline(program, []int{0, 1, 2}, Chain, 0); err == nil {
+		t.Errorf("RunPipeline() longer chain error = nil, want an error when the input runs out")
+	}
+	if _, err := RunPipeline([]int{104, 1, 99}, []int{0, 1}, FeedbackLoop, 0); err != nil {
+		t.Errorf("RunPipeline() error = %v for machines that ignore their input", err)
+	}
+}
+
+func TestPermutations(t *testing.T) {
+	seen := map[[3]int]bool{}
+	Permutations([]int{1, 2, 3}, func(perm []int) bool {
+		seen[[3]int{perm[0], perm[1], perm[2]}] = true
+		return true
+	})
+	if len(seen) != 6 {
+		t.Errorf("Permutations() visited %d distinct orderings, want 6", len(seen))
+	}
+}
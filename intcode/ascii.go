@@ -0,0 +1,80 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package intcode
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+//asciiLimit is the first value that is no longer an ASCII character code
+const asciiLimit = 128
+
+//EncodeASCII returns the input codes for a line of text, ending with the newline ASCII programs expect after each line
+func EncodeASCII(line string) []int {
+	codes := make([]int, 0, len(line)+1)
+	for _, c := range line {
+		codes = append(codes, int(c))
+	}
+	return append(codes, '\n')
+}
+
+//ASCIIInput reads lines of text from r and provides them to the program one character code at a time, each line
+//followed by a newline. When echo isn't nil every line is written to it as it is read, so a scripted input file shows
+//up in the transcript the same way typed input would
+func ASCIIInput(r io.Reader, echo io.Writer) InputSource {
+	return &asciiInput{r: bufio.NewReader(r), echo: echo}
+}
+
+type asciiInput struct {
+	r       *bufio.Reader
+	echo    io.Writer
+	pending []int
+}
+
+func (a *asciiInput) ReadInt() (int, error) {
+	if len(a.pending) == 0 {
+		line, err := a.r.ReadString('\n')
+		//the last line of a file doesn't need a newline to be sent
+		if err == io.EOF && line == "" {
+			return 0, io.EOF
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if a.echo != nil {
+			if _, err := fmt.Fprintln(a.echo, line); err != nil {
+				return 0, err
+			}
+		}
+		a.pending = EncodeASCII(line)
+	}
+	val := a.pending[0]
+	a.pending = a.pending[1:]
+	return val, nil
+}
+
+//ASCIIOutput writes values below 128 to w as the characters they encode. Anything else can't be a character, such as
+//the answer a program prints at the end, and is written as a number on a line of its own
+func ASCIIOutput(w io.Writer) OutputSink {
+	return &asciiOutput{w: w}
+}
+
+type asciiOutput struct {
+	w io.Writer
+}
+
+func (a *asciiOutput) WriteInt(val int) error {
+	if val >= 0 && val < asciiLimit {
+		_, err := a.w.Write([]byte{byte(val)})
+		return err
+	}
+	_, err := fmt.Fprintf(a.w, "%d\n", val)
+	return err
+}
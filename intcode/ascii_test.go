@@ -0,0 +1,62 @@
+package intcode
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+//echoLine echoes characters until it echoes a newline, then outputs 1000
+var echoLine = []int{3, 100, 4, 100, 1008, 100, 10, 101, 1006, 101, 0, 104, 1000, 99}
+
+func TestASCII(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		echo  string
+		err   error
+	}{
+		{name: "line", input: "hi\nthere\n", want: "hi\n1000\n", echo: "hi\n"},
+		{name: "no trailing newline", input: "north", want: "north\n1000\n", echo: "north\n"},
+		{name: "windows line ending", input: "go\r\n", want: "go\n1000\n", echo: "go\n"},
+		{name: "empty line", input: "\n", want: "\n1000\n", echo: "\n"},
+		{name: "exhausted", input: "", want: "", echo: "", err: ErrInputExhausted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, echo strings.Builder
+			i := InitIO(echoLine, ASCIIInput(strings.NewReader(tt.input), &echo), ASCIIOutput(&out))
+			_, err := i.Run()
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Run() error = %v, want %v", err, tt.err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("output = %q, want %q", out.String(), tt.want)
+			}
+			if echo.String() != tt.echo {
+				t.Errorf("echo = %q, want %q", echo.String(), tt.echo)
+			}
+		})
+	}
+}
+
+func TestASCIIOutput(t *testing.T) {
+	var out strings.Builder
+	sink := ASCIIOutput(&out)
+	for _, val := range []int{'o', 'k', '\n', 128, -1, 19690720, '!'} {
+		if err := sink.WriteInt(val); err != nil {
+			t.Fatalf("WriteInt(%d) error = %v", val, err)
+		}
+	}
+	if want := "ok\n128\n-1\n19690720\n!"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestEncodeASCII(t *testing.T) {
+	if got, want := EncodeASCII("NOT A J"), []int{78, 79, 84, 32, 65, 32, 74, 10}; !reflect.DeepEqual(got, want) {
+		t.Errorf("EncodeASCII() = %v, want %v", got, want)
+	}
+}
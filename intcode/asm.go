@@ -0,0 +1,326 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package intcode
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//maxMacroDepth limits how deeply macros can expand other macros, so a macro that uses itself fails instead of hanging
+const maxMacroDepth = 32
+
+var (
+	labelPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_.]*):`)
+	identPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.%]*$`)
+	//wordPattern matches the identifiers of a macro body that may be macro parameters
+	wordPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_.%]*`)
+)
+
+//Assemble translates Intcode assembly source into a program. The source has one statement per line, and anything after
+//a ';' is a comment. A statement is made up of:
+//
+//	label:                   defines label as the address of whatever follows it
+//	ADD a, b, c              any mnemonic from Mnemonics followed by its comma separated operands
+//	.data 1, -2, @label      raw values placed directly into the program
+//	NAME a, b                an invocation of a macro defined with .macro
+//
+//Operands are written the same way the disassembler prints them: a bare value is an immediate mode parameter, [value]
+//is a position mode parameter and [rb+value] or [rb-value] is a relative mode parameter. A value is either a number or a
+//label reference such as @loop or @loop+2.
+//
+//Macros are defined between .macro NAME param1, param2 and .endm. Every use of a parameter in the body is replaced by
+//the matching argument, and any '%' in an identifier is replaced with a number unique to each expansion, so labels
+//such as skip%: can be defined inside of a macro that is used more than once
+func Assemble(src io.Reader) ([]int, error) {
+	a := &assembler{
+		macros: map[string]*macro{},
+		labels: map[string]int{},
+	}
+	scanner := bufio.NewScanner(src)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		if err := a.addLine(scanner.Text(), lineNum); err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if a.defining != nil {
+		return nil, errors.New(fmt.Sprintf("line %d: macro %s is never closed with .endm", a.defining.line, a.defining.name))
+	}
+	return a.encode()
+}
+
+//FormatProgram formats a program as the comma separated tape read by LoadIntCodeProgram
+func FormatProgram(program []int) string {
+	vals := make([]string, len(program))
+	for idx, val := range program {
+		vals[idx] = strconv.Itoa(val)
+	}
+	return strings.Join(vals, ",")
+}
+
+type macro struct {
+	name   string
+	line   int
+	params []string
+	body   []string
+}
+
+//statement is an instruction or .data directive that has been placed at an address
+type statement struct {
+	line int
+	op   string
+	args []string
+	addr int
+}
+
+type assembler struct {
+	macros     map[string]*macro
+	defining   *macro
+	labels     map[string]int
+	statements []statement
+	addr       int
+	expansions int
+}
+
+func (a *assembler) addLine(text string, lineNum int) error {
+	if idx := strings.Index(text, ";"); idx >= 0 {
+		text = text[:idx]
+	}
+	text = strings.TrimSpace(text)
+	op, args := splitStatement(text)
+
+	if a.defining != nil {
+		if strings.EqualFold(op, ".endm") {
+			a.macros[strings.ToUpper(a.defining.name)] = a.defining
+			a.defining = nil
+			return nil
+		}
+		if strings.EqualFold(op, ".macro") {
+			return errors.New(fmt.Sprintf("line %d: macros cannot be defined inside of macro %s", lineNum, a.defining.name))
+		}
+		a.defining.body = append(a.defining.body, text)
+		return nil
+	}
+	if strings.EqualFold(op, ".macro") {
+		if len(args) == 0 {
+			return errors.New(fmt.Sprintf("line %d: .macro needs a name", lineNum))
+		}
+		//the name and first parameter are only separated by whitespace
+		fields := strings.Fields(args[0])
+		m := &macro{name: fields[0], line: lineNum, params: fields[1:]}
+		if len(m.params) > 1 {
+			return errors.New(fmt.Sprintf("line %d: macro parameters must be separated by commas", lineNum))
+		}
+		m.params = append(m.params, args[1:]...)
+		for _, name := range append([]string{m.name}, m.params...) {
+			if !identPattern.MatchString(name) {
+				return errors.New(fmt.Sprintf("line %d: '%s' is not a valid macro or parameter name", lineNum, name))
+			}
+		}
+		if _, ok := mnemonicOpcodes[strings.ToUpper(m.name)]; ok {
+			return errors.New(fmt.Sprintf("line %d: macro %s has the same name as an instruction", lineNum, m.name))
+		}
+		a.defining = m
+		return nil
+	}
+	if strings.EqualFold(op, ".endm") {
+		return errors.New(fmt.Sprintf("line %d: .endm without a matching .macro", lineNum))
+	}
+	return a.addStatement(text, lineNum, 0)
+}
+
+//addStatement places the labels and the instruction, directive or macro expansion of a line
+func (a *assembler) addStatement(text string, lineNum int, depth int) error {
+	for {
+		match := labelPattern.FindStringSubmatch(text)
+		if match == nil {
+			break
+		}
+		if _, ok := a.labels[match[1]]; ok {
+			return errors.New(fmt.Sprintf("line %d: label %s is defined more than once", lineNum, match[1]))
+		}
+		a.labels[match[1]] = a.addr
+		text = strings.TrimSpace(text[len(match[0]):])
+	}
+	op, args := splitStatement(text)
+	if op == "" {
+		return nil
+	}
+
+	if strings.EqualFold(op, ".data") {
+		if len(args) == 0 {
+			return errors.New(fmt.Sprintf("line %d: .data needs at least one value", lineNum))
+		}
+		a.statements = append(a.statements, statement{line: lineNum, op: ".data", args: args, addr: a.addr})
+		a.addr += len(args)
+		return nil
+	}
+	if opcode, ok := mnemonicOpcodes[strings.ToUpper(op)]; ok {
+		if len(args) != paramCount[opcode] {
+			return errors.New(fmt.Sprintf("line %d: %s takes %d operands, found %d", lineNum, strings.ToUpper(op), paramCount[opcode], len(args)))
+		}
+		a.statements = append(a.statements, statement{line: lineNum, op: strings.ToUpper(op), args: args, addr: a.addr})
+		a.addr += len(args) + 1
+		return nil
+	}
+	m, ok := a.macros[strings.ToUpper(op)]
+	if !ok {
+		return errors.New(fmt.Sprintf("line %d: unknown instruction, directive or macro '%s'", lineNum, op))
+	}
+	if depth >= maxMacroDepth {
+		return errors.New(fmt.Sprintf("line %d: macros expanded more than %d levels deep", lineNum, maxMacroDepth))
+	}
+	if len(args) != len(m.params) {
+		return errors.New(fmt.Sprintf("line %d: macro %s takes %d arguments, found %d", lineNum, m.name, len(m.params), len(args)))
+	}
+	a.expansions++
+	unique := strconv.Itoa(a.expansions)
+	replacements := make(map[string]string, len(m.params))
+	for idx, param := range m.params {
+		replacements[param] = args[idx]
+	}
+	for _, line := range m.body {
+		expanded := wordPattern.ReplaceAllStringFunc(line, func(word string) string {
+			if arg, ok := replacements[word]; ok {
+				return arg
+			}
+			return strings.Replace(word, "%", "."+unique, -1)
+		})
+		if err := a.addStatement(expanded, lineNum, depth+1); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("in macro %s", m.name))
+		}
+	}
+	return nil
+}
+
+//encode resolves the operands of every placed statement into the final program
+func (a *assembler) encode() ([]int, error) {
+	program := make([]int, 0, a.addr)
+	for _, s := range a.statements {
+		if s.op == ".data" {
+			for _, arg := range s.args {
+				val, err := a.value(arg)
+				if err != nil {
+					return nil, errors.New(fmt.Sprintf("line %d: %s", s.line, err))
+				}
+				program = append(program, val)
+			}
+			continue
+		}
+		opcode := mnemonicOpcodes[s.op]
+		instruction := opcode
+		operands := make([]int, len(s.args))
+		scale := 100
+		for idx, arg := range s.args {
+			val, mode, err := a.operand(arg)
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("line %d: %s", s.line, err))
+			}
+			if w, ok := writeParam[opcode]; ok && w == idx && mode == 1 {
+				return nil, errors.New(fmt.Sprintf("line %d: operand %d of %s is written to and cannot be immediate", s.line, idx+1, s.op))
+			}
+			instruction += mode * scale
+			scale *= 10
+			operands[idx] = val
+		}
+		program = append(program, instruction)
+		program = append(program, operands...)
+	}
+	return program, nil
+}
+
+//operand parses a single operand into its value and parameter mode
+func (a *assembler) operand(arg string) (int, int, error) {
+	if !strings.HasPrefix(arg, "[") {
+		val, err := a.value(arg)
+		return val, 1, err
+	}
+	if !strings.HasSuffix(arg, "]") {
+		return 0, 0, errors.New(fmt.Sprintf("operand '%s' is missing a closing ]", arg))
+	}
+	inner := strings.TrimSpace(arg[1 : len(arg)-1])
+	if !strings.HasPrefix(strings.ToLower(inner), "rb") {
+		val, err := a.value(inner)
+		return val, 0, err
+	}
+	offset := strings.TrimSpace(inner[2:])
+	if offset == "" {
+		return 0, 2, nil
+	}
+	sign := 1
+	switch offset[0] {
+	case '+':
+	case '-':
+		sign = -1
+	default:
+		return 0, 0, errors.New(fmt.Sprintf("relative operand '%s' must look like [rb+offset] or [rb-offset]", arg))
+	}
+	val, err := a.value(strings.TrimSpace(offset[1:]))
+	return sign * val, 2, err
+}
+
+//value resolves a number or a label reference with an optional offset, such as @loop+2
+func (a *assembler) value(expr string) (int, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "@") {
+		val, err := strconv.Atoi(expr)
+		if err != nil {
+			return 0, errors.New(fmt.Sprintf("'%s' is not a number or label reference", expr))
+		}
+		return val, nil
+	}
+	name := expr[1:]
+	offset := 0
+	if idx := strings.IndexAny(name, "+-"); idx >= 0 {
+		var err error
+		offset, err = strconv.Atoi(strings.TrimSpace(name[idx:]))
+		if err != nil {
+			return 0, errors.New(fmt.Sprintf("'%s' has an invalid label offset", expr))
+		}
+		name = strings.TrimSpace(name[:idx])
+	}
+	addr, ok := a.labels[name]
+	if !ok {
+		return 0, errors.New(fmt.Sprintf("undefined label '%s'", name))
+	}
+	return addr + offset, nil
+}
+
+//splitStatement splits a statement into its operation and its comma separated arguments
+func splitStatement(text string) (string, []string) {
+	idx := strings.IndexAny(text, " \t")
+	if idx < 0 {
+		return text, nil
+	}
+	op, rest := text[:idx], strings.TrimSpace(text[idx:])
+	if rest == "" {
+		return op, nil
+	}
+	args := strings.Split(rest, ",")
+	for idx := range args {
+		args[idx] = strings.TrimSpace(args[idx])
+	}
+	return op, args
+}
+
+//mnemonicOpcodes is the reverse of Mnemonics
+var mnemonicOpcodes = func() map[string]int {
+	opcodes := make(map[string]int, len(Mnemonics))
+	for opcode, mnemonic := range Mnemonics {
+		opcodes[mnemonic] = opcode
+	}
+	return opcodes
+}()
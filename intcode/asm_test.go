@@ -0,0 +1,153 @@
+package intcode
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAssemble(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    []int
+		wantErr bool
+	}{
+		{
+			name: "d5_p2_example1_position-mode",
+			src: `
+				IN [@val]
+				EQ [@val], [@eight], [@val]
+				OUT [@val]
+				HLT
+			val:   .data -1
+			eight: .data 8`,
+			want: []int{3, 9, 8, 9, 10, 9, 4, 9, 99, -1, 8},
+		},
+		{
+			name: "d5_p2_example3_immediate-mode",
+			src: `
+				IN [@cmp+1]    ; overwrite the first operand of the comparison
+			cmp: EQ -1, 8, [@cmp+1]
+				OUT [@cmp+1]
+				HLT`,
+			want: []int{3, 3, 1108, -1, 8, 3, 4, 3, 99},
+		},
+		{
+			name: "relative_mode_and_labels_before_use",
+			src: `
+				ARB @buf
+				IN [rb]
+				JT [rb+0], @out
+				OUT [rb-1]
+			out: OUT [rb]
+				HLT
+				.data 7
+			buf: .data 0`,
+			want: []int{109, 13, 203, 0, 1205, 0, 9, 204, -1, 204, 0, 99, 7, 0},
+		},
+		{
+			name: "macros",
+			src: `
+			.macro MOV src, dst
+				ADD src, 0, dst
+			.endm
+			.macro ABS cell
+				LT cell, 0, [@tmp]
+				JF [@tmp], @skip%
+				MUL cell, -1, cell
+			skip%:
+			.endm
+				IN [@x]
+				ABS [@x]
+				ABS [@x]
+				MOV [@x], [@y]
+				OUT [@y]
+				HLT
+			x:   .data 0
+			y:   .data 0
+			tmp: .data 0`,
+			want: []int{3, 31, 1007, 31, 0, 33, 1006, 33, 13, 1002, 31, -1, 31,
+				1007, 31, 0, 33, 1006, 33, 24, 1002, 31, -1, 31, 1001, 31, 0, 32, 4, 32, 99, 0, 0, 0},
+		},
+		{
+			name:    "undefined_label",
+			src:     "JT 1, @nowhere",
+			wantErr: true,
+		},
+		{
+			name:    "immediate_write",
+			src:     "ADD 1, 2, 3",
+			wantErr: true,
+		},
+		{
+			name:    "wrong_operand_count",
+			src:     "OUT [1], [2]",
+			wantErr: true,
+		},
+		{
+			name:    "recursive_macro",
+			src:     ".macro LOOP\nLOOP\n.endm\nLOOP",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Assemble(strings.NewReader(tt.src))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Assemble() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Assemble() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssemble_Runs(t *testing.T) {
+	program, err := Assemble(strings.NewReader(`
+		.macro ABS cell
+			LT cell, 0, [@tmp]
+			JF [@tmp], @skip%
+			MUL cell, -1, cell
+		skip%:
+		.endm
+		loop: IN [@x]
+			JF [@x], @done
+			ABS [@x]
+			OUT [@x]
+			JT 1, @loop
+		done: HLT
+		x:   .data 0
+		tmp: .data 0`))
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	out := &SliceOutput{}
+	if _, err := InitIO(program, NewSliceInput(-3, 4, -5, 0), out).Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := []int{3, 4, 5}; !reflect.DeepEqual(out.Values, want) {
+		t.Errorf("Run() output = %v, want %v", out.Values, want)
+	}
+}
+
+func TestAssemble_Disassembly(t *testing.T) {
+	//the listing printed by the disassembler assembles back into the same program
+	program := []int{3, 225, 1, 225, 6, 6, 1101, 1, 238, 225, 109, -2, 22201, 1, 2, 3, 99}
+	var src strings.Builder
+	for _, in := range Disassemble(program) {
+		if in.IsData() {
+			src.WriteString(".data " + strings.Join(in.Operands(), ", ") + "\n")
+			continue
+		}
+		src.WriteString(in.Mnemonic + " " + strings.Join(in.Operands(), ", ") + "\n")
+	}
+	got, err := Assemble(strings.NewReader(src.String()))
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, program) {
+		t.Errorf("Assemble() = %v, want %v", got, program)
+	}
+}
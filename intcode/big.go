@@ -0,0 +1,281 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package intcode
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"math/big"
+	"os"
+)
+
+//BigIntcode is an Intcode machine whose memory cells are arbitrary precision integers, so arithmetic never overflows.
+//Addresses, jump targets and the relative base still have to fit in an int
+type BigIntcode struct {
+	memory       map[int]*big.Int
+	pos          int
+	relativeBase int
+	halted       bool
+	in           *bufio.Scanner
+	out          io.Writer
+}
+
+//InitBig creates a new arbitrary precision Intcode machine that will run the passed in program. The program is copied
+//into the machine's memory
+func InitBig(program []*big.Int, in io.Reader, out io.Writer) *BigIntcode {
+	b := &BigIntcode{
+		memory: make(map[int]*big.Int, len(program)),
+		out:    out,
+	}
+	if in != nil {
+		//input is split the same way as TextInput, so both backends accept the same whitespace or comma separated values
+		b.in = bufio.NewScanner(in)
+		b.in.Split(scanValues)
+	}
+	for addr, val := range program {
+		b.Poke(addr, val)
+	}
+	return b
+}
+
+//ToBig converts an int program into one that can be loaded by InitBig
+func ToBig(program []int) []*big.Int {
+	converted := make([]*big.Int, len(program))
+	for idx, val := range program {
+		converted[idx] = big.NewInt(int64(val))
+	}
+	return converted
+}
+
+//LoadBigIntCodeProgram reads a comma separated Intcode program from the passed in file, allowing values that do not fit
+//in an int
+func LoadBigIntCodeProgram(filename string) ([]*big.Int, error) {
+	csvfile, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer csvfile.Close()
+
+	tape, err := csv.NewReader(csvfile).Read()
+	if err == io.EOF {
+		return nil, errors.New("input file did not contain an Intcode program")
+	}
+	if err != nil {
+		return nil, err
+	}
+	intcodes := make([]*big.Int, 0, len(tape))
+	for _, opcode := range tape {
+		val, ok := new(big.Int).SetString(opcode, 10)
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("unable to convert opcode '%s' to a number", opcode))
+		}
+		intcodes = append(intcodes, val)
+	}
+	return intcodes, nil
+}
+
+//Run executes the program from the current instruction pointer until it halts with opcode 99. The arbitrary precision
+//backend always reads from and writes to its text streams, so it never pauses for input or output, but like Intcode.Run
+//it resumes where it left off when called again after an error
+func (b *BigIntcode) Run() (Status, error) {
+	if b.halted {
+		return Halted, nil
+	}
+	for {
+		opcode, next, err := b.HandleInstruction(b.pos)
+		if _, ok := err.(*VMError); ok {
+			return Running, err
+		}
+		if err != nil {
+			return Running, errors.Wrap(err, fmt.Sprintf("error encountered at position %d", b.pos))
+		}
+		if opcode == 99 {
+			b.halted = true
+			return Halted, nil
+		}
+		b.pos = next
+	}
+}
+
+//Pos returns the current instruction pointer
+func (b *BigIntcode) Pos() int {
+	return b.pos
+}
+
+//Peek returns a copy of the value stored at the passed in address of the machine's memory
+func (b *BigIntcode) Peek(addr int) *big.Int {
+	return new(big.Int).Set(b.read(addr))
+}
+
+//Poke stores a copy of the passed in value at the passed in address of the machine's memory
+func (b *BigIntcode) Poke(addr int, val *big.Int) {
+	b.write(addr, new(big.Int).Set(val))
+}
+
+//HandleInstruction handles a single opcode instruction at the passed in position.
+//It will return the opcode that was processed and the new position of the program
+func (b *BigIntcode) HandleInstruction(pos int) (int, int, error) {
+	if pos < 0 {
+		return -1, -1, b.vmError(ErrAddressOutOfRange, pos, -1, pos)
+	}
+	instruction, ok := toInt(b.read(pos))
+	if !ok {
+		return -1, -1, errors.New(fmt.Sprintf("instruction %s at position %d does not fit in an int", b.read(pos), pos))
+	}
+	if instruction == 99 {
+		return 99, pos, nil
+	}
+	opcode, par1, par2, par3 := decode(instruction)
+
+	//only resolve the parameters the opcode actually takes, the values after them could be anything
+	modes := [3]int{par1, par2, par3}
+	if param, err := checkInstruction(opcode, modes); err != nil {
+		return -1, -1, b.vmError(err, pos, param, 0)
+	}
+	var locs [3]int
+	for p := 0; p < paramCount[opcode]; p++ {
+		if pos+1+p < 0 {
+			//the parameter would be past the highest address
+			return -1, -1, b.vmError(ErrAddressOutOfRange, pos, p, pos+1+p)
+		}
+		var ok bool
+		locs[p], ok = b.paramLocation(pos+1+p, modes[p])
+		if !ok || locs[p] < 0 {
+			return -1, -1, b.vmError(ErrAddressOutOfRange, pos, p, locs[p])
+		}
+	}
+	loc1, loc2, loc3 := locs[0], locs[1], locs[2]
+	switch opcode {
+	case 1:
+		b.write(loc3, new(big.Int).Add(b.read(loc1), b.read(loc2)))
+		pos += 4
+	case 2:
+		b.write(loc3, new(big.Int).Mul(b.read(loc1), b.read(loc2)))
+		pos += 4
+	case 3:
+		//takes a single integer as input and saves it to the position given by its only parameter
+		val := new(big.Int)
+		if b.in == nil {
+			return -1, -1, errors.New(fmt.Sprintf("no input connected when requested at position %d", pos))
+		}
+		if !b.in.Scan() {
+			if err := b.in.Err(); err != nil {
+				return -1, -1, errors.Wrap(err, fmt.Sprintf("error reading input at position %d", pos))
+			}
+			return -1, -1, b.vmError(ErrInputExhausted, pos, -1, 0)
+		}
+		if _, ok := val.SetString(b.in.Text(), 10); !ok {
+			return -1, -1, errors.New(fmt.Sprintf("unable to parse an integer from input '%s' at position %d", b.in.Text(), pos))
+		}
+		b.write(loc1, val)
+		pos += 2
+	case 4:
+		//outputs the value of its only parameter
+		_, err := fmt.Fprintf(b.out, "%s\n", b.read(loc1))
+		if err != nil {
+			return -1, -1, errors.Wrap(err, fmt.Sprintf("io error: unable to write value %s to output", b.read(loc1)))
+		}
+		pos += 2
+	case 5, 6:
+		//jump-if-true (5) and jump-if-false (6)
+		isZero := b.read(loc1).Sign() == 0
+		if isZero != (opcode == 6) {
+			pos += 3
+			break
+		}
+		target, ok := toInt(b.read(loc2))
+		if !ok {
+			return -1, -1, b.vmError(ErrAddressOutOfRange, pos, 1, -1)
+		}
+		pos = target
+	case 7:
+		//less than
+		b.write(loc3, boolToBig(b.read(loc1).Cmp(b.read(loc2)) < 0))
+		pos += 4
+	case 8:
+		//equals
+		b.write(loc3, boolToBig(b.read(loc1).Cmp(b.read(loc2)) == 0))
+		pos += 4
+	case 9:
+		//relative base offset
+		offset, ok := toInt(b.read(loc1))
+		if !ok {
+			//the relative base could never be used as an address again
+			return -1, -1, b.vmError(ErrAddressOutOfRange, pos, 0, -1)
+		}
+		b.relativeBase += offset
+		pos += 2
+	}
+	return opcode, pos, nil
+}
+
+//paramLocation returns the address of the parameter at addr, or false when the address doesn't fit in an int, in which
+//case the returned address is -1
+func (b *BigIntcode) paramLocation(addr int, mode int) (int, bool) {
+	switch mode {
+	case 1:
+		return addr, true
+	case 2:
+		offset, ok := toInt(b.read(addr))
+		if !ok || addOverflows(b.relativeBase, offset) {
+			return -1, false
+		}
+		return b.relativeBase + offset, true
+	default:
+		return toInt(b.read(addr))
+	}
+}
+
+//vmError builds the VMError for the instruction at pos. Cells too large for an int are reported as -1 in its Memory
+func (b *BigIntcode) vmError(err error, pos int, param int, addr int) *VMError {
+	e := &VMError{Err: err, Pos: pos, Param: param, Addr: addr}
+	for addr := pos; addr >= 0 && addr < pos+4; addr++ {
+		val, _ := toInt(b.read(addr))
+		e.Memory = append(e.Memory, val)
+	}
+	if len(e.Memory) > 0 {
+		e.Instruction = e.Memory[0]
+	}
+	return e
+}
+
+//read returns the value stored at addr without copying it, so callers must not modify it
+func (b *BigIntcode) read(addr int) *big.Int {
+	checkAddress(addr)
+	val, ok := b.memory[addr]
+	if !ok {
+		return new(big.Int)
+	}
+	return val
+}
+
+func (b *BigIntcode) write(addr int, val *big.Int) {
+	checkAddress(addr)
+	if val.Sign() == 0 {
+		delete(b.memory, addr)
+		return
+	}
+	b.memory[addr] = val
+}
+
+//toInt converts a value used as an address, instruction or offset into an int, returning -1 and false when it doesn't
+//fit in one
+func toInt(val *big.Int) (int, bool) {
+	if !val.IsInt64() || val.Int64() > int64(maxInt) || val.Int64() < int64(minInt) {
+		return -1, false
+	}
+	return int(val.Int64()), true
+}
+
+func boolToBig(b bool) *big.Int {
+	if b {
+		return big.NewInt(1)
+	}
+	return new(big.Int)
+}
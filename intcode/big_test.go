@@ -0,0 +1,131 @@
+package intcode
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNew_Backends(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []int
+		in      string
+		backend Backend
+		wantErr bool
+		outWant string
+	}{
+		{
+			name:    "int_large_multiply",
+			program: []int{1102, 34915192, 34915192, 7, 4, 7, 99, 0},
+			backend: IntBackend,
+			outWant: "1219070632396864\n",
+		},
+		{
+			name:    "big_large_multiply",
+			program: []int{1102, 34915192, 34915192, 7, 4, 7, 99, 0},
+			backend: BigBackend,
+			outWant: "1219070632396864\n",
+		},
+		{
+			name:    "int_multiply_overflow",
+			program: []int{1102, 1 << 62, 4, 7, 4, 7, 99, 0},
+			backend: IntBackend,
+			wantErr: true,
+		},
+		{
+			name:    "big_multiply_overflow",
+			program: []int{1102, 1 << 62, 4, 7, 4, 7, 99, 0},
+			backend: BigBackend,
+			outWant: "18446744073709551616\n",
+		},
+		{
+			name:    "int_add_overflow",
+			program: []int{1101, maxInt, 1, 7, 4, 7, 99, 0},
+			backend: IntBackend,
+			wantErr: true,
+		},
+		{
+			name:    "big_add_negative_overflow",
+			program: []int{1101, minInt, -1, 7, 4, 7, 99, 0},
+			backend: BigBackend,
+			outWant: "-9223372036854775809\n",
+		},
+		{
+			name:    "big_d5_compare_and_jump",
+			program: []int{3, 12, 6, 12, 15, 1, 13, 14, 13, 4, 13, 99, -1, 0, 1, 9},
+			in:      "-1",
+			backend: BigBackend,
+			outWant: "1\n",
+		},
+		{
+			name:    "int_comma_separated_input",
+			program: []int{3, 11, 3, 12, 1, 11, 12, 13, 4, 13, 99, 0, 0, 0},
+			in:      "3,4",
+			backend: IntBackend,
+			outWant: "7\n",
+		},
+		{
+			name:    "big_comma_separated_input",
+			program: []int{3, 11, 3, 12, 1, 11, 12, 13, 4, 13, 99, 0, 0, 0},
+			in:      "3,4",
+			backend: BigBackend,
+			outWant: "7\n",
+		},
+		{
+			name:    "big_relative_mode_input",
+			program: []int{109, 12, 109, -3, 203, 0, 204, 0, 99, 0},
+			in:      "17",
+			backend: BigBackend,
+			outWant: "17\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			m := New(tt.program, strings.NewReader(tt.in), &b, tt.backend)
+			if _, err := m.Run(); (err != nil) != tt.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if output := b.String(); output != tt.outWant {
+				t.Errorf("Run() output = %s, wantOut %s", output, tt.outWant)
+			}
+		})
+	}
+}
+
+func TestIntcode_Run_OverflowError(t *testing.T) {
+	i := Init([]int{1, 0, 0, 0, 1102, 1 << 62, 4, 9, 99, 0}, nil, nil)
+	_, err := i.Run()
+	var overflow *OverflowError
+	if !errors.As(err, &overflow) {
+		t.Fatalf("Run() error = %v, want an OverflowError", err)
+	}
+	if overflow.Pos != 4 || overflow.Instruction != 1102 || overflow.A != 1<<62 || overflow.B != 4 {
+		t.Errorf("OverflowError = %+v, want position 4, instruction 1102, operands %d and 4", overflow, 1<<62)
+	}
+}
+
+func TestBigIntcode_Run_Resumable(t *testing.T) {
+	var out strings.Builder
+	b := InitBig(ToBig([]int{104, 1, 3, 0, 99}), strings.NewReader(""), &out)
+	for run := 1; run <= 2; run++ {
+		if _, err := b.Run(); !errors.Is(err, ErrInputExhausted) || b.Pos() != 2 {
+			t.Fatalf("Run() #%d error = %v at position %d, want %v at position 2", run, err, b.Pos(), ErrInputExhausted)
+		}
+	}
+	if out.String() != "1\n" {
+		t.Errorf("output = %q, want the program not to start over", out.String())
+	}
+
+	b = InitBig(ToBig([]int{104, 1, 99}), nil, &out)
+	for run := 1; run <= 2; run++ {
+		if status, err := b.Run(); status != Halted || err != nil {
+			t.Errorf("Run() #%d = %v, %v, want Halted", run, status, err)
+		}
+	}
+	if out.String() != "1\n1\n" {
+		t.Errorf("output = %q, want a halted machine to stay halted", out.String())
+	}
+}
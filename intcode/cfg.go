@@ -0,0 +1,236 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package intcode
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+//EdgeKind is how control passes from one block to another
+type EdgeKind int
+
+const (
+	//EdgeFallthrough is execution continuing at the next instruction, either because the block ended at a jump that was
+	//not taken or because the next instruction starts a new block
+	EdgeFallthrough EdgeKind = iota
+	//EdgeJump is a jump with an immediate target being taken
+	EdgeJump
+)
+
+func (k EdgeKind) String() string {
+	if k == EdgeJump {
+		return "jump"
+	}
+	return "fallthrough"
+}
+
+//Edge is a transfer of control between the blocks starting at From and To. To may not be the start of any block when a
+//jump targets the middle of an instruction or an address past the end of the program
+type Edge struct {
+	From int
+	To   int
+	Kind EdgeKind
+}
+
+//Block is a basic block: a run of instructions that is only ever entered at its first instruction and only ever left
+//after its last one. Cells that don't decode as an instruction are kept in blocks of their own, made of DATA entries
+type Block struct {
+	Start        int
+	Instructions []Instruction
+	//Reachable is whether the block can be reached from position 0 by following the edges of the graph
+	Reachable bool
+	//Modified is whether an instruction with a static target writes into any of the block's cells
+	Modified bool
+	//Indirect is the operand of the jump ending the block when its target is only known at runtime
+	Indirect string
+}
+
+//End returns the address just past the block's last cell
+func (b *Block) End() int {
+	last := b.Instructions[len(b.Instructions)-1]
+	return last.Addr + len(last.Cells)
+}
+
+//IsData returns whether the block holds data rather than instructions
+func (b *Block) IsData() bool {
+	return b.Instructions[0].IsData()
+}
+
+//CFG is the static control-flow graph of a program
+type CFG struct {
+	//Blocks are ordered by their start address and cover every cell of the program
+	Blocks []*Block
+	Edges  []Edge
+}
+
+//BuildCFG splits the disassembly of a program into basic blocks and connects them with the jumps and fallthroughs that
+//can be seen statically. Jumps whose target is only known at runtime are recorded on their block as Indirect and have
+//no edges, so code only reached through them is reported as unreachable
+func BuildCFG(program []int) *CFG {
+	listing := Disassemble(program)
+
+	//a block starts at position 0, at every immediate jump target, after every jump or halt and wherever the listing
+	//switches between data and instructions
+	leaders := map[int]bool{0: true}
+	for idx, in := range listing {
+		if in.IsData() {
+			leaders[in.Addr] = true
+			if idx+1 < len(listing) {
+				leaders[listing[idx+1].Addr] = true
+			}
+			continue
+		}
+		if in.Opcode == 5 || in.Opcode == 6 || in.Opcode == 99 {
+			leaders[in.Addr+len(in.Cells)] = true
+		}
+		if (in.Opcode == 5 || in.Opcode == 6) && in.Modes[1] == 1 {
+			leaders[in.Cells[2]] = true
+		}
+	}
+
+	g := &CFG{}
+	for _, in := range listing {
+		//consecutive data entries stay in the same block
+		if leaders[in.Addr] && !(in.IsData() && len(g.Blocks) > 0 && g.Blocks[len(g.Blocks)-1].IsData()) {
+			g.Blocks = append(g.Blocks, &Block{Start: in.Addr})
+		}
+		b := g.Blocks[len(g.Blocks)-1]
+		b.Instructions = append(b.Instructions, in)
+	}
+
+	for idx, b := range g.Blocks {
+		if b.IsData() {
+			continue
+		}
+		last := b.Instructions[len(b.Instructions)-1]
+		hasNext := idx+1 < len(g.Blocks)
+		switch last.Opcode {
+		case 99:
+		case 5, 6:
+			jumpsIfTrue := last.Opcode == 5
+			condKnown := last.Modes[0] == 1
+			condTrue := last.Cells[1] != 0
+			if (!condKnown || condTrue != jumpsIfTrue) && hasNext {
+				g.Edges = append(g.Edges, Edge{From: b.Start, To: b.End(), Kind: EdgeFallthrough})
+			}
+			if condKnown && condTrue != jumpsIfTrue {
+				break
+			}
+			if last.Modes[1] == 1 {
+				g.Edges = append(g.Edges, Edge{From: b.Start, To: last.Cells[2], Kind: EdgeJump})
+			} else {
+				b.Indirect = FormatOperand(last.Cells[2], last.Modes[1])
+			}
+		default:
+			if hasNext {
+				g.Edges = append(g.Edges, Edge{From: b.Start, To: b.End(), Kind: EdgeFallthrough})
+			}
+		}
+	}
+
+	g.markReachable()
+	g.markModified()
+	return g
+}
+
+//Block returns the block containing addr, or nil if no block does
+func (g *CFG) Block(addr int) *Block {
+	idx := sort.Search(len(g.Blocks), func(idx int) bool { return g.Blocks[idx].End() > addr })
+	if idx == len(g.Blocks) || g.Blocks[idx].Start > addr {
+		return nil
+	}
+	return g.Blocks[idx]
+}
+
+//markReachable walks the edges from the block at position 0
+func (g *CFG) markReachable() {
+	out := map[int][]int{}
+	for _, e := range g.Edges {
+		out[e.From] = append(out[e.From], e.To)
+	}
+	work := []int{0}
+	for len(work) > 0 {
+		addr := work[len(work)-1]
+		work = work[:len(work)-1]
+		b := g.Block(addr)
+		if b == nil || b.Start != addr || b.Reachable {
+			continue
+		}
+		b.Reachable = true
+		work = append(work, out[addr]...)
+	}
+}
+
+//markModified flags the blocks written to by instructions with a position mode target
+func (g *CFG) markModified() {
+	for _, b := range g.Blocks {
+		for _, in := range b.Instructions {
+			idx, ok := writeParam[in.Opcode]
+			if !ok || in.IsData() || in.Modes[idx] != 0 {
+				continue
+			}
+			if target := g.Block(in.Cells[idx+1]); target != nil {
+				target.Modified = true
+			}
+		}
+	}
+}
+
+//WriteDOT writes the graph in Graphviz DOT format. Unreachable blocks are dashed and grey, blocks that are written to
+//at runtime are red and indirect jumps point at a diamond holding their target operand
+func (g *CFG) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph intcode {")
+	fmt.Fprintln(bw, "\tnode [shape=box fontname=\"monospace\"];")
+	for _, b := range g.Blocks {
+		var attrs []string
+		if b.IsData() {
+			attrs = append(attrs, "shape=note")
+		}
+		color := ""
+		if !b.Reachable {
+			attrs = append(attrs, "style=dashed", "fontcolor=grey")
+			color = "grey"
+		}
+		if b.Modified {
+			color = "red"
+		}
+		if color != "" {
+			attrs = append(attrs, "color="+color)
+		}
+		attrs = append(attrs, fmt.Sprintf("label=\"%s\"", blockLabel(b)))
+		fmt.Fprintf(bw, "\tb%d [%s];\n", b.Start, strings.Join(attrs, " "))
+		if b.Indirect != "" {
+			fmt.Fprintf(bw, "\tb%d_indirect [shape=diamond label=\"%s\"];\n", b.Start, b.Indirect)
+			fmt.Fprintf(bw, "\tb%d -> b%d_indirect [style=dotted label=\"indirect\"];\n", b.Start, b.Start)
+		}
+	}
+	for _, e := range g.Edges {
+		if target := g.Block(e.To); target == nil || target.Start != e.To {
+			//the jump lands somewhere that isn't the start of a block, give it a node of its own
+			//targets can be negative, so the node name has to be quoted
+			fmt.Fprintf(bw, "\t\"invalid%d\" [shape=octagon color=red label=\"no block starts at %d\"];\n", e.To, e.To)
+			fmt.Fprintf(bw, "\tb%d -> \"invalid%d\" [label=\"%s\"];\n", e.From, e.To, e.Kind)
+			continue
+		}
+		fmt.Fprintf(bw, "\tb%d -> b%d [label=\"%s\"];\n", e.From, e.To, e.Kind)
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+//blockLabel lists the block's instructions one per left-justified line
+func blockLabel(b *Block) string {
+	var sb strings.Builder
+	for _, in := range b.Instructions {
+		fmt.Fprintf(&sb, "%d: %s %s\\l", in.Addr, in.Mnemonic, strings.Join(in.Operands(), ", "))
+	}
+	return sb.String()
+}
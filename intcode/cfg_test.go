@@ -0,0 +1,74 @@
+package intcode
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildCFG(t *testing.T) {
+	//the same program as TestDisassemble: the jump always skips over the data and the ADD, which is written to by the IN
+	//and itself writes to position 0
+	program := []int{3, 6, 1105, 1, 10, 42, 1101, 0, 0, 0, 109, 5, 204, -1, 99}
+	g := BuildCFG(program)
+
+	type block struct {
+		start     int
+		count     int
+		reachable bool
+		modified  bool
+	}
+	want := []block{
+		{0, 2, true, true},
+		{5, 1, false, false},
+		{6, 1, false, true},
+		{10, 3, true, false},
+	}
+	got := make([]block, len(g.Blocks))
+	for idx, b := range g.Blocks {
+		got[idx] = block{b.Start, len(b.Instructions), b.Reachable, b.Modified}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildCFG() blocks = %+v, want %+v", got, want)
+	}
+	wantEdges := []Edge{{From: 0, To: 10, Kind: EdgeJump}, {From: 6, To: 10, Kind: EdgeFallthrough}}
+	if !reflect.DeepEqual(g.Edges, wantEdges) {
+		t.Errorf("BuildCFG() edges = %+v, want %+v", g.Edges, wantEdges)
+	}
+	if b := g.Block(12); b == nil || b.Start != 10 {
+		t.Errorf("Block(12) = %+v, want the block at 10", b)
+	}
+}
+
+func TestBuildCFG_Indirect(t *testing.T) {
+	//jumps to the address held at 10 when the value at 9 is zero, which is only known at runtime
+	program := []int{6, 9, 10, 104, 1, 99, 104, 2, 99, 0, 6}
+	g := BuildCFG(program)
+	if len(g.Blocks) != 4 {
+		t.Fatalf("BuildCFG() returned %d blocks, want 4", len(g.Blocks))
+	}
+	if got := g.Blocks[0].Indirect; got != "[10]" {
+		t.Errorf("block 0 Indirect = %q, want [10]", got)
+	}
+	if g.Blocks[2].Reachable {
+		t.Errorf("block at %d is reachable, it is only reached through the indirect jump", g.Blocks[2].Start)
+	}
+
+	var buf bytes.Buffer
+	if err := g.WriteDOT(&buf); err != nil {
+		t.Fatalf("WriteDOT() error = %v", err)
+	}
+	dot := buf.String()
+	for _, want := range []string{
+		"digraph intcode {",
+		"b0 -> b3 [label=\"fallthrough\"];",
+		"b0_indirect [shape=diamond label=\"[10]\"];",
+		"b6 [style=dashed fontcolor=grey color=grey",
+		"label=\"3: OUT 1\\l5: HLT \\l\"",
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("WriteDOT() is missing %q:\n%s", want, dot)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package intcode
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"go/format"
+	"go/token"
+	"io"
+	"strings"
+)
+
+//NativeMemoryLimit is the highest address compiled code keeps in its flat memory. A write past it hands the program
+//over to the interpreter, whose paged memory copes with sparse addresses
+const NativeMemoryLimit = 1 << 20
+
+//NativeState is the state of a program compiled by Compile while it runs as Go code. Compiled code only calls its
+//methods, and hands the state over to an interpreter machine whenever it reaches something it was not compiled for
+type NativeState struct {
+	//Mem is the program's memory, it is always exactly as long as the highest address used plus one
+	Mem          []int
+	RelativeBase int
+	Steps        int
+	Output       int
+	In           InputSource
+	Out          OutputSink
+}
+
+//NewNativeState loads a copy of program for compiled code to run, connected to the passed in input and output
+func NewNativeState(program []int, in InputSource, out OutputSink) *NativeState {
+	return &NativeState{Mem: append([]int(nil), program...), In: in, Out: out}
+}
+
+//Load returns the value at addr, which must not be negative
+func (s *NativeState) Load(addr int) int {
+	if addr < len(s.Mem) {
+		return s.Mem[addr]
+	}
+	return 0
+}
+
+//Store sets the value at addr, which must not be negative or above NativeMemoryLimit
+func (s *NativeState) Store(addr int, val int) {
+	if addr >= len(s.Mem) {
+		if addr < cap(s.Mem) {
+			s.Mem = s.Mem[:addr+1]
+		} else {
+			grown := make([]int, addr+1, 2*addr+1)
+			copy(grown, s.Mem)
+			s.Mem = grown
+		}
+	}
+	s.Mem[addr] = val
+}
+
+//Add returns a + b, or false if the sum overflows
+func (s *NativeState) Add(a int, b int) (int, bool) {
+	return a + b, !addOverflows(a, b)
+}
+
+//Mul returns a * b, or false if the product overflows
+func (s *NativeState) Mul(a int, b int) (int, bool) {
+	return a * b, !mulOverflows(a, b)
+}
+
+//Input reads the value for the input instruction at pos
+func (s *NativeState) Input(pos int) (int, error) {
+	val, err := s.In.ReadInt()
+	if err == io.EOF {
+		return 0, s.Machine(pos).vmError(ErrInputExhausted, pos, -1, 0)
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("error reading input at position %d", pos))
+	}
+	return val, nil
+}
+
+//Emit writes the value of an output instruction to the output sink
+func (s *NativeState) Emit(val int) error {
+	s.Output = val
+	if err := s.Out.WriteInt(val); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("io error: unable to write value %d to output", val))
+	}
+	return nil
+}
+
+//Machine converts the state into an interpreter machine about to execute the instruction at pos
+func (s *NativeState) Machine(pos int) *Intcode {
+	pages := map[int][]int{}
+	for addr, val := range s.Mem {
+		if val == 0 {
+			continue
+		}
+		page, ok := pages[addr/PageSize]
+		if !ok {
+			page = make([]int, PageSize)
+			pages[addr/PageSize] = page
+		}
+		page[addr%PageSize] = val
+	}
+	i := InitIO(nil, s.In, s.Out)
+	i.Restore(&Snapshot{
+		Pages:        pages,
+		Size:         len(s.Mem),
+		Pos:          pos,
+		RelativeBase: s.RelativeBase,
+		Steps:        s.Steps,
+		Output:       s.Output,
+	})
+	return i
+}
+
+//Fallback hands the program over to the interpreter at pos and runs it from there
+func (s *NativeState) Fallback(pos int) (*Intcode, Status, error) {
+	i := s.Machine(pos)
+	status, err := i.Run()
+	return i, status, err
+}
+
+//Halt returns the halted machine after executing the halt instruction at pos
+func (s *NativeState) Halt(pos int) (*Intcode, Status, error) {
+	i := s.Machine(pos)
+	i.halted = true
+	return i, Halted, nil
+}
+
+//Pause returns the machine paused after producing output with no OutputSink connected, about to execute pos
+func (s *NativeState) Pause(pos int) (*Intcode, Status, error) {
+	return s.Machine(pos), HasOutput, nil
+}
+
+//Fail returns the machine along with the error of the instruction at pos, wrapped the same way Step wraps it
+func (s *NativeState) Fail(pos int, err error) (*Intcode, Status, error) {
+	if _, ok := err.(*VMError); ok {
+		return s.Machine(pos), Running, err
+	}
+	return s.Machine(pos), Running, errors.Wrap(err, fmt.Sprintf("error encountered at position %d", pos))
+}
+
+//Compile translates a program into Go source for a package called pkg holding a single function called name:
+//
+//	func name(in intcode.InputSource, out intcode.OutputSink) (*intcode.Intcode, intcode.Status, error)
+//
+//Every instruction found by Disassemble becomes a case of a switch on the instruction pointer, falling through to the
+//next one wherever execution can. The function returns the machine along with the same status and error Intcode.Run
+//would. Whenever the compiled code can't carry on by itself, because the program jumps into code that was not compiled,
+//writes into its own code, needs input or produces output with nothing connected, overflows or uses a negative address,
+//it hands its state over to an interpreter machine and runs that instead
+func Compile(w io.Writer, program []int, pkg string, name string) error {
+	if !token.IsIdentifier(pkg) || !token.IsIdentifier(name) {
+		return errors.New(fmt.Sprintf("'%s' and '%s' must both be Go identifiers", pkg, name))
+	}
+	var code []Instruction
+	//compiled marks every cell that belongs to a compiled instruction, writing to one leaves the compiled code
+	compiled := make([]bool, len(program))
+	for _, in := range Disassemble(program) {
+		if in.IsData() {
+			continue
+		}
+		code = append(code, in)
+		for cell := in.Addr; cell < in.Addr+len(in.Cells); cell++ {
+			compiled[cell] = true
+		}
+	}
+
+	var src strings.Builder
+	fmt.Fprintf(&src, "// Code generated by intcode.Compile. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	fmt.Fprintln(&src, `import "github.com/mjourard/aoc-2019/intcode"`)
+	fmt.Fprintf(&src, "\n//%sProgram is the program %s was compiled from\nvar %sProgram = []int{%s}\n", name, name, name,
+		joinInts(program))
+	fmt.Fprintf(&src, "\n//%sCode marks the cells of %sProgram that hold compiled instructions\nvar %sCode = []bool{", name, name, name)
+	for idx, c := range compiled {
+		if idx%16 == 0 {
+			src.WriteString("\n")
+		}
+		fmt.Fprintf(&src, "%v,", c)
+	}
+	src.WriteString("\n}\n")
+
+	fmt.Fprintf(&src, "\n//%s runs %sProgram as native Go code. It returns the machine left behind along with the same "+
+		"status and error that\n//Intcode.Run would return\n", name, name)
+	fmt.Fprintf(&src, "func %s(in intcode.InputSource, out intcode.OutputSink) (*intcode.Intcode, intcode.Status, error) {\n", name)
+	fmt.Fprintf(&src, "s := intcode.NewNativeState(%sProgram, in, out)\npc := 0\nfor {\nswitch pc {\n", name)
+	for idx, in := range code {
+		next := in.Addr + len(in.Cells)
+		fmt.Fprintf(&src, "case %d:\n// %s %s\n", in.Addr, in.Mnemonic, strings.Join(in.Operands(), ", "))
+		c := compiler{src: &src, in: in, name: name, compiled: compiled}
+		if !c.instruction() {
+			continue
+		}
+		if idx+1 < len(code) && code[idx+1].Addr == next {
+			src.WriteString("fallthrough\n")
+		} else {
+			fmt.Fprintf(&src, "pc = %d\n", next)
+		}
+	}
+	src.WriteString("default:\nreturn s.Fallback(pc)\n}\n}\n}\n")
+
+	formatted, err := format.Source([]byte(src.String()))
+	if err != nil {
+		return errors.Wrap(err, "generated source does not compile")
+	}
+	_, err = w.Write(formatted)
+	return err
+}
+
+//compiler generates the body of a single instruction's case
+type compiler struct {
+	src      *strings.Builder
+	in       Instruction
+	name     string
+	compiled []bool
+}
+
+//instruction writes the body of the instruction's case. It returns whether execution can continue at the next
+//instruction afterwards
+func (c *compiler) instruction() bool {
+	in := c.in
+	next := in.Addr + len(in.Cells)
+	if idx, writes := writeParam[in.Opcode]; writes && in.Modes[idx] == 1 {
+		//leave it to the interpreter to report ErrWriteImmediate
+		c.printf("return s.Fallback(%d)\n", in.Addr)
+		return false
+	}
+	operands := make([]string, len(in.Modes))
+	for idx := range in.Modes {
+		if _, writes := writeParam[in.Opcode]; writes && writeParam[in.Opcode] == idx {
+			continue
+		}
+		val, ok := c.read(idx)
+		if !ok {
+			return false
+		}
+		operands[idx] = val
+	}
+
+	switch in.Opcode {
+	case 1, 2:
+		op := "Add"
+		if in.Opcode == 2 {
+			op = "Mul"
+		}
+		c.printf("v, ok := s.%s(%s, %s)\nif !ok {\nreturn s.Fallback(%d)\n}\n", op, operands[0], operands[1], in.Addr)
+		return c.write(2, "v")
+	case 3:
+		c.printf("if s.In == nil {\nreturn s.Fallback(%d)\n}\n", in.Addr)
+		c.printf("v, err := s.Input(%d)\nif err != nil {\nreturn s.Fail(%d, err)\n}\n", in.Addr, in.Addr)
+		return c.write(0, "v")
+	case 4:
+		c.printf("if s.Out == nil {\ns.Output = %s\ns.Steps++\nreturn s.Pause(%d)\n}\n", operands[0], next)
+		c.printf("if err := s.Emit(%s); err != nil {\nreturn s.Fail(%d, err)\n}\ns.Steps++\n", operands[0], in.Addr)
+	case 5, 6:
+		cond := "!="
+		if in.Opcode == 6 {
+			cond = "=="
+		}
+		c.printf("s.Steps++\nif %s %s 0 {\npc = %s\ncontinue\n}\n", operands[0], cond, operands[1])
+	case 7, 8:
+		cmp := "<"
+		if in.Opcode == 8 {
+			cmp = "=="
+		}
+		c.printf("v := 0\nif %s %s %s {\nv = 1\n}\n", operands[0], cmp, operands[1])
+		return c.write(2, "v")
+	case 9:
+		c.printf("s.RelativeBase += %s\ns.Steps++\n", operands[0])
+	case 99:
+		c.printf("s.Steps++\nreturn s.Halt(%d)\n", in.Addr)
+		return false
+	}
+	return true
+}
+
+//read returns the expression for the value of parameter idx. It returns false, after making the instruction fall back
+//to the interpreter, when the parameter is statically known to be at a negative address
+func (c *compiler) read(idx int) (string, bool) {
+	param := c.in.Cells[idx+1]
+	switch c.in.Modes[idx] {
+	case 1:
+		return fmt.Sprintf("%d", param), true
+	case 2:
+		c.printf("a%d := s.RelativeBase + %d\nif a%d < 0 {\nreturn s.Fallback(%d)\n}\n", idx, param, idx, c.in.Addr)
+		return fmt.Sprintf("s.Load(a%d)", idx), true
+	}
+	if param < 0 {
+		c.printf("return s.Fallback(%d)\n", c.in.Addr)
+		return "", false
+	}
+	return fmt.Sprintf("s.Load(%d)", param), true
+}
+
+//write stores val to the location of parameter idx, then counts the instruction as executed. When the location is
+//compiled code, the rest of the program is handed over to the interpreter so that it runs the modified code
+func (c *compiler) write(idx int, val string) bool {
+	in := c.in
+	next := in.Addr + len(in.Cells)
+	param := in.Cells[idx+1]
+	switch in.Modes[idx] {
+	case 2:
+		c.printf("d := s.RelativeBase + %d\nif d < 0 || d > intcode.NativeMemoryLimit {\nreturn s.Fallback(%d)\n}\n", param, in.Addr)
+		c.printf("s.Store(d, %s)\ns.Steps++\n", val)
+		c.printf("if d < len(%sCode) && %sCode[d] {\nreturn s.Fallback(%d)\n}\n", c.name, c.name, next)
+		return true
+	}
+	if param < 0 || param > NativeMemoryLimit {
+		c.printf("return s.Fallback(%d)\n", in.Addr)
+		return false
+	}
+	c.printf("s.Store(%d, %s)\ns.Steps++\n", param, val)
+	if param < len(c.compiled) && c.compiled[param] {
+		c.printf("return s.Fallback(%d)\n", next)
+		return false
+	}
+	return true
+}
+
+func (c *compiler) printf(f string, args ...interface{}) {
+	fmt.Fprintf(c.src, f, args...)
+}
+
+func joinInts(vals []int) string {
+	strs := make([]string, len(vals))
+	for idx, val := range vals {
+		strs[idx] = fmt.Sprintf("%d", val)
+	}
+	return strings.Join(strs, ", ")
+}
@@ -0,0 +1,34 @@
+package intcode
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	//the ADD overwrites the opcode of the OUT that follows it
+	program := []int{1101, 1, 3, 4, 4, 0, 99}
+	var buf bytes.Buffer
+	if err := Compile(&buf, program, "progs", "SelfModifying"); err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	src := buf.String()
+	for _, want := range []string{
+		"package progs",
+		"func SelfModifying(in intcode.InputSource, out intcode.OutputSink) (*intcode.Intcode, intcode.Status, error) {",
+		//the write into the OUT leaves the compiled code for the interpreter
+		"s.Store(4, v)\n\t\t\ts.Steps++\n\t\t\treturn s.Fallback(4)",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("Compile() is missing %q:\n%s", want, src)
+		}
+	}
+}
+
+func TestCompile_InvalidName(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Compile(&buf, []int{99}, "progs", "not-a-name"); err == nil {
+		t.Errorf("Compile() with an invalid function name succeeded")
+	}
+}
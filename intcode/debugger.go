@@ -0,0 +1,385 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package intcode
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//debuggerHelp lists the commands understood by the debugger
+const debuggerHelp = `commands:
+  s, step [n]            execute n instructions (default 1)
+  c, continue            run until a breakpoint, watchpoint, input request or halt
+  b, break <addr>        break before executing the instruction at addr
+  b, break op <opcode>   break before executing any instruction with the opcode, given as a number or mnemonic
+  w, watch <addr>        break after the value at addr changes
+  d, delete <addr>       remove the breakpoint at addr
+  d, delete op <opcode>  remove the opcode breakpoint
+  unwatch <addr>         remove the watchpoint on addr
+  i, info                list breakpoints and watchpoints
+  p, print               show the instruction pointer, relative base and the current instruction
+  x <addr> [count]       dump count memory cells starting at addr (default 8)
+  set <addr> <value>     patch the memory cell at addr
+  input <values...>      queue values for the program's input instructions
+  save <file>            save the machine's state to a snapshot file
+  load <file>            restore the machine's state from a snapshot file
+  q, quit                exit the debugger`
+
+//Debugger steps through an Intcode machine, stopping on breakpoints and watchpoints and letting the caller inspect
+//and patch memory in between
+type Debugger struct {
+	machine       *Intcode
+	out           io.Writer
+	breakpoints   map[int]bool
+	opcodeBreaks  map[int]bool
+	watches       map[int]int
+	status        Status
+	lastDisplayed int
+}
+
+//NewDebugger creates a debugger for the passed in machine that writes everything it reports to out. Output produced
+//by the program is reported too, unless the machine has its own OutputSink connected
+func NewDebugger(machine *Intcode, out io.Writer) *Debugger {
+	return &Debugger{
+		machine:      machine,
+		out:          out,
+		breakpoints:  map[int]bool{},
+		opcodeBreaks: map[int]bool{},
+		watches:      map[int]int{},
+		status:       Running,
+	}
+}
+
+//Repl reads commands from in, one per line, until the input ends or the quit command is given
+func (d *Debugger) Repl(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	d.printCurrent()
+	for {
+		fmt.Fprint(d.out, "(intcode) ")
+		if !scanner.Scan() {
+			fmt.Fprintln(d.out)
+			return scanner.Err()
+		}
+		quit, err := d.Exec(scanner.Text())
+		if err != nil {
+			fmt.Fprintf(d.out, "error: %v\n", err)
+		}
+		if quit {
+			return nil
+		}
+	}
+}
+
+//Exec runs a single debugger command. It returns true once the debugger should exit
+func (d *Debugger) Exec(line string) (bool, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false, nil
+	}
+	args := fields[1:]
+	switch strings.ToLower(fields[0]) {
+	case "q", "quit", "exit":
+		return true, nil
+	case "h", "help", "?":
+		fmt.Fprintln(d.out, debuggerHelp)
+	case "s", "step":
+		count := 1
+		if len(args) > 0 {
+			var err error
+			if count, err = strconv.Atoi(args[0]); err != nil {
+				return false, errors.New(fmt.Sprintf("invalid step count '%s'", args[0]))
+			}
+		}
+		for n := 0; n < count; n++ {
+			if stop, err := d.step(); err != nil || stop {
+				return false, err
+			}
+		}
+		d.printCurrent()
+	case "c", "continue":
+		return false, d.Continue()
+	case "b", "break":
+		return false, d.toggleBreak(args, true)
+	case "d", "delete":
+		return false, d.toggleBreak(args, false)
+	case "w", "watch":
+		addr, err := parseArg(args, 0, "address")
+		if err != nil {
+			return false, err
+		}
+		d.watches[addr] = d.machine.Peek(addr)
+	case "unwatch":
+		addr, err := parseArg(args, 0, "address")
+		if err != nil {
+			return false, err
+		}
+		delete(d.watches, addr)
+	case "i", "info":
+		d.printBreakpoints()
+	case "p", "print":
+		d.printCurrent()
+	case "x":
+		addr, err := parseArg(args, 0, "address")
+		if err != nil {
+			return false, err
+		}
+		count := 8
+		if len(args) > 1 {
+			if count, err = parseArg(args, 1, "count"); err != nil {
+				return false, err
+			}
+		}
+		d.dump(addr, count)
+	case "set":
+		addr, err := parseArg(args, 0, "address")
+		if err != nil {
+			return false, err
+		}
+		val, err := parseArg(args, 1, "value")
+		if err != nil {
+			return false, err
+		}
+		d.machine.Poke(addr, val)
+		if _, ok := d.watches[addr]; ok {
+			d.watches[addr] = val
+		}
+	case "input":
+		if len(args) == 0 {
+			return false, errors.New("input needs at least one value")
+		}
+		for idx := range args {
+			val, err := parseArg(args, idx, "input value")
+			if err != nil {
+				return false, err
+			}
+			d.machine.Input(val)
+		}
+	case "save":
+		if len(args) == 0 {
+			return false, errors.New("missing snapshot file")
+		}
+		if err := SaveSnapshotFile(d.machine.Snapshot(), args[0]); err != nil {
+			return false, err
+		}
+		fmt.Fprintf(d.out, "saved snapshot to %s\n", args[0])
+	case "load":
+		if len(args) == 0 {
+			return false, errors.New("missing snapshot file")
+		}
+		snapshot, err := LoadSnapshotFile(args[0])
+		if err != nil {
+			return false, err
+		}
+		d.machine.Restore(snapshot)
+		d.status = Running
+		if snapshot.Halted {
+			d.status = Halted
+		}
+		for addr := range d.watches {
+			d.watches[addr] = d.machine.Peek(addr)
+		}
+		d.printCurrent()
+	default:
+		return false, errors.New(fmt.Sprintf("unknown command '%s', try help", fields[0]))
+	}
+	return false, nil
+}
+
+//Continue runs the machine until it hits a breakpoint or watchpoint, needs input, halts or fails. A breakpoint on the
+//instruction the machine is currently stopped at does not stop it again
+func (d *Debugger) Continue() error {
+	first := true
+	for {
+		if !first && d.atBreakpoint() {
+			d.printCurrent()
+			return nil
+		}
+		first = false
+		stop, err := d.step()
+		if err != nil || stop {
+			return err
+		}
+	}
+}
+
+//step executes a single instruction, reporting anything that should stop execution. It returns true when the
+//debugger should stop
+func (d *Debugger) step() (bool, error) {
+	if d.status == Halted {
+		fmt.Fprintln(d.out, "program has halted")
+		return true, nil
+	}
+	pos := d.machine.Pos()
+	status, err := d.machine.Step()
+	if err != nil {
+		return true, err
+	}
+	d.status = status
+	switch status {
+	case NeedsInput:
+		fmt.Fprintf(d.out, "program needs input at %d, queue some with: input <values...>\n", pos)
+		return true, nil
+	case HasOutput:
+		fmt.Fprintf(d.out, "output: %d\n", d.machine.Output())
+	case Halted:
+		fmt.Fprintf(d.out, "program halted at %d\n", pos)
+		return true, nil
+	}
+
+	stop := false
+	addrs := make([]int, 0, len(d.watches))
+	for addr := range d.watches {
+		addrs = append(addrs, addr)
+	}
+	sort.Ints(addrs)
+	for _, addr := range addrs {
+		old := d.watches[addr]
+		if val := d.machine.Peek(addr); val != old {
+			fmt.Fprintf(d.out, "watchpoint: [%d] changed from %d to %d by the instruction at %d\n", addr, old, val, pos)
+			d.watches[addr] = val
+			stop = true
+		}
+	}
+	if stop {
+		d.printCurrent()
+	}
+	return stop, nil
+}
+
+//atBreakpoint reports whether the next instruction to execute has a breakpoint on its address or opcode
+func (d *Debugger) atBreakpoint() bool {
+	pos := d.machine.Pos()
+	if d.breakpoints[pos] {
+		fmt.Fprintf(d.out, "breakpoint at %d\n", pos)
+		return true
+	}
+	if pos < 0 {
+		//there is no instruction to check, the next step fails with an out of range error
+		return false
+	}
+	opcode, _, _, _ := decode(d.machine.Peek(pos))
+	if d.opcodeBreaks[opcode] {
+		fmt.Fprintf(d.out, "opcode breakpoint on %s at %d\n", Mnemonics[opcode], pos)
+		return true
+	}
+	return false
+}
+
+func (d *Debugger) toggleBreak(args []string, set bool) error {
+	if len(args) > 0 && strings.ToLower(args[0]) == "op" {
+		if len(args) < 2 {
+			return errors.New("missing opcode")
+		}
+		opcode, ok := mnemonicOpcodes[strings.ToUpper(args[1])]
+		if !ok {
+			var err error
+			if opcode, err = strconv.Atoi(args[1]); err != nil {
+				return errors.New(fmt.Sprintf("unknown opcode '%s'", args[1]))
+			}
+		}
+		if set {
+			d.opcodeBreaks[opcode] = true
+		} else {
+			delete(d.opcodeBreaks, opcode)
+		}
+		return nil
+	}
+	addr, err := parseArg(args, 0, "address")
+	if err != nil {
+		return err
+	}
+	if set {
+		d.breakpoints[addr] = true
+	} else {
+		delete(d.breakpoints, addr)
+	}
+	return nil
+}
+
+//printCurrent shows the machine state and the instruction at the instruction pointer along with the resolved value of
+//each of its parameters
+func (d *Debugger) printCurrent() {
+	pos := d.machine.Pos()
+	in, ok := d.machine.Decode(pos)
+	fmt.Fprintf(d.out, "ip=%d rb=%d  %s\n", pos, d.machine.RelativeBase(), strings.TrimSpace(in.String()))
+	if !ok {
+		return
+	}
+	resolved := make([]string, 0, len(in.Modes))
+	for idx, mode := range in.Modes {
+		if mode == 1 {
+			continue
+		}
+		loc := d.machine.paramLocation(pos+1+idx, mode)
+		if loc < 0 {
+			resolved = append(resolved, fmt.Sprintf("[%d]=?", loc))
+			continue
+		}
+		resolved = append(resolved, fmt.Sprintf("[%d]=%d", loc, d.machine.Peek(loc)))
+	}
+	if len(resolved) > 0 {
+		fmt.Fprintf(d.out, "    %s\n", strings.Join(resolved, " "))
+	}
+}
+
+func (d *Debugger) printBreakpoints() {
+	list := func(label string, set map[int]bool, format func(int) string) {
+		keys := make([]int, 0, len(set))
+		for key := range set {
+			keys = append(keys, key)
+		}
+		sort.Ints(keys)
+		names := make([]string, len(keys))
+		for idx, key := range keys {
+			names[idx] = format(key)
+		}
+		fmt.Fprintf(d.out, "%s: %s\n", label, strings.Join(names, ", "))
+	}
+	list("breakpoints", d.breakpoints, strconv.Itoa)
+	list("opcode breakpoints", d.opcodeBreaks, func(opcode int) string {
+		if mnemonic, ok := Mnemonics[opcode]; ok {
+			return mnemonic
+		}
+		return strconv.Itoa(opcode)
+	})
+	watched := make(map[int]bool, len(d.watches))
+	for addr := range d.watches {
+		watched[addr] = true
+	}
+	list("watchpoints", watched, func(addr int) string {
+		return fmt.Sprintf("[%d]=%d", addr, d.watches[addr])
+	})
+}
+
+func (d *Debugger) dump(addr int, count int) {
+	for row := addr; row < addr+count; row += 8 {
+		vals := make([]string, 0, 8)
+		for cell := row; cell < row+8 && cell < addr+count; cell++ {
+			vals = append(vals, strconv.Itoa(d.machine.Peek(cell)))
+		}
+		fmt.Fprintf(d.out, "%6d: %s\n", row, strings.Join(vals, " "))
+	}
+}
+
+func parseArg(args []string, idx int, what string) (int, error) {
+	if idx >= len(args) {
+		return 0, errors.New(fmt.Sprintf("missing %s", what))
+	}
+	val, err := strconv.Atoi(args[idx])
+	if err != nil {
+		return 0, errors.New(fmt.Sprintf("invalid %s '%s'", what, args[idx]))
+	}
+	if what == "address" && val < 0 {
+		return 0, errors.New(fmt.Sprintf("invalid %s '%s'", what, args[idx]))
+	}
+	return val, nil
+}
@@ -0,0 +1,108 @@
+package intcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDebugger_Repl(t *testing.T) {
+	//d5_p2 example 1: outputs 1 if the input is 8, 0 otherwise
+	program := []int{3, 9, 8, 9, 10, 9, 4, 9, 99, -1, 8}
+	tests := []struct {
+		name     string
+		commands []string
+		want     []string
+		dontWant []string
+	}{
+		{
+			name:     "needs_input_then_runs",
+			commands: []string{"c", "input 8", "c"},
+			want:     []string{"program needs input at 0", "output: 1", "program halted at 8"},
+		},
+		{
+			name:     "address_breakpoint",
+			commands: []string{"input 7", "break 6", "c", "x 9 2"},
+			want:     []string{"breakpoint at 6", "ip=6 rb=0", "OUT  [9]", "[9]=0", "     9: 0 8"},
+			dontWant: []string{"output:"},
+		},
+		{
+			name:     "opcode_breakpoint",
+			commands: []string{"input 8", "break op EQ", "c", "info"},
+			want:     []string{"opcode breakpoint on EQ at 2", "[9]=8 [10]=8 [9]=8", "opcode breakpoints: EQ"},
+		},
+		{
+			name:     "watchpoint",
+			commands: []string{"input 3", "watch 9", "c", "c"},
+			want: []string{"watchpoint: [9] changed from -1 to 3 by the instruction at 0",
+				"watchpoint: [9] changed from 3 to 0 by the instruction at 2"},
+		},
+		{
+			name:     "patch_memory",
+			commands: []string{"input 3", "set 10 3", "c"},
+			want:     []string{"output: 1"},
+		},
+		{
+			name:     "step",
+			commands: []string{"input 8", "step 2", "s"},
+			want:     []string{"ip=6 rb=0", "OUT  [9]", "output: 1", "ip=8 rb=0"},
+		},
+		{
+			name:     "bad_commands",
+			commands: []string{"bogus", "x", "break op NOPE"},
+			want:     []string{"error: unknown command 'bogus'", "error: missing address", "error: unknown opcode 'NOPE'"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			d := NewDebugger(InitIO(program, nil, nil), &out)
+			if err := d.Repl(strings.NewReader(strings.Join(tt.commands, "\n"))); err != nil {
+				t.Fatalf("Repl() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("Repl() output does not contain %q:\n%s", want, out.String())
+				}
+			}
+			for _, dontWant := range tt.dontWant {
+				if strings.Contains(out.String(), dontWant) {
+					t.Errorf("Repl() output contains %q:\n%s", dontWant, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestDebugger_Repl_NegativeJump(t *testing.T) {
+	program := []int{1105, 1, -5, 99}
+	tests := []struct {
+		name     string
+		commands []string
+		want     []string
+	}{
+		{
+			name:     "step",
+			commands: []string{"step", "step"},
+			want:     []string{"ip=-5 rb=0", "DATA", "error: address out of range: the instruction pointer moved to -5"},
+		},
+		{
+			name:     "continue_with_opcode_breakpoint",
+			commands: []string{"break op ADD", "c"},
+			want:     []string{"error: address out of range: the instruction pointer moved to -5"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			d := NewDebugger(InitIO(program, nil, nil), &out)
+			if err := d.Repl(strings.NewReader(strings.Join(tt.commands, "\n"))); err != nil {
+				t.Fatalf("Repl() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("Repl() output does not contain %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package intcode
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+)
+
+//decodeCacheLimit is the highest address that decoded instructions are cached for. Instructions past it are decoded
+//every time they run, which only matters for programs that jump far outside of where they were loaded
+const decodeCacheLimit = 1 << 16
+
+//decodedInstruction is an instruction decoded once and cached by the memory it was read from. The location of each
+//parameter is worked out at decode time, only relative mode parameters need the relative base added when they run
+type decodedInstruction struct {
+	opcode int
+	//length is the number of cells the instruction takes up, including its parameters
+	length int
+	//locs are the locations of the parameters, offsets from the relative base for relative mode parameters
+	locs     [3]int
+	relative [3]bool
+	//hasRelative is set when any parameter is in relative mode, as only those can end up at a negative address
+	hasRelative bool
+	exec        func(i *Intcode, pos int, d *decodedInstruction) (int, error)
+}
+
+//handlers executes each opcode from its decoded form, returning the position of the next instruction
+var handlers = map[int]func(i *Intcode, pos int, d *decodedInstruction) (int, error){
+	1:  execAdd,
+	2:  execMul,
+	3:  execInput,
+	4:  execOutput,
+	5:  execJumpIfTrue,
+	6:  execJumpIfFalse,
+	7:  execLessThan,
+	8:  execEquals,
+	9:  execAdjustRelativeBase,
+	99: execHalt,
+}
+
+//decodeInstruction decodes the instruction at pos, returning nil if it is not a valid instruction. Invalid instructions
+//are left to HandleInstruction, which reports why they are invalid
+func decodeInstruction(m *Memory, pos int) *decodedInstruction {
+	instruction := m.Read(pos)
+	opcode, par1, par2, par3 := decode(instruction)
+	modes := [3]int{par1, par2, par3}
+	if _, err := checkInstruction(opcode, modes); err != nil {
+		return nil
+	}
+	d := &decodedInstruction{opcode: opcode, length: paramCount[opcode] + 1, exec: handlers[opcode]}
+	for idx, mode := range modes[:paramCount[opcode]] {
+		switch mode {
+		case 1:
+			d.locs[idx] = pos + idx + 1
+		case 2:
+			d.locs[idx] = m.Read(pos + idx + 1)
+			d.relative[idx] = true
+			d.hasRelative = true
+		default:
+			d.locs[idx] = m.Read(pos + idx + 1)
+			if d.locs[idx] < 0 {
+				return nil
+			}
+		}
+	}
+	return d
+}
+
+//decodeChunk is the number of addresses in each chunk of the decoded instruction cache. Forks share chunks the same
+//way they share memory pages, copying a chunk the first time they change it
+const decodeChunk = 64
+
+//decodedAt returns the decoded instruction at pos, decoding and caching it if it hasn't been run since it was last
+//written to. It returns nil if the instruction can't be cached
+func (m *Memory) decodedAt(pos int) *decodedInstruction {
+	if pos < 0 || pos >= decodeCacheLimit {
+		return nil
+	}
+	if num := pos / decodeChunk; num < len(m.decoded) && m.decoded[num] != nil {
+		if d := m.decoded[num][pos%decodeChunk]; d != nil {
+			return d
+		}
+	}
+	d := decodeInstruction(m, pos)
+	if d == nil {
+		return nil
+	}
+	m.decodedChunk(pos / decodeChunk)[pos%decodeChunk] = d
+	return d
+}
+
+//invalidate drops every cached instruction that addr is a part of
+func (m *Memory) invalidate(addr int) {
+	//no instruction is longer than 4 cells, so only those starting at most 3 cells before addr can contain it
+	for start := addr; start > addr-4 && start >= 0; start-- {
+		num := start / decodeChunk
+		if num >= len(m.decoded) || m.decoded[num] == nil {
+			continue
+		}
+		if d := m.decoded[num][start%decodeChunk]; d != nil && start+d.length > addr {
+			m.decodedChunk(num)[start%decodeChunk] = nil
+		}
+	}
+}
+
+//decodedChunk returns chunk num of the decoded instruction cache, ready to be modified. The chunk is allocated if it
+//doesn't exist yet and copied if it is shared with a fork
+func (m *Memory) decodedChunk(num int) []*decodedInstruction {
+	for num >= len(m.decoded) {
+		m.decoded = append(m.decoded, nil)
+		m.decodedOwned = append(m.decodedOwned, false)
+	}
+	switch {
+	case m.decoded[num] == nil:
+		m.decoded[num] = make([]*decodedInstruction, decodeChunk)
+	case !m.decodedOwned[num]:
+		m.decoded[num] = append([]*decodedInstruction(nil), m.decoded[num]...)
+	}
+	m.decodedOwned[num] = true
+	return m.decoded[num]
+}
+
+//forkDecoded shares the decoded instruction cache with a fork. Like Fork, the original is only modified when it owns
+//any chunks
+func (m *Memory) forkDecoded(fork *Memory) {
+	fork.decoded = append([][]*decodedInstruction(nil), m.decoded...)
+	fork.decodedOwned = make([]bool, len(m.decoded))
+	for _, owned := range m.decodedOwned {
+		if owned {
+			m.decodedOwned = make([]bool, len(m.decoded))
+			break
+		}
+	}
+}
+
+//Predecode fills the decoded instruction cache with every instruction Disassemble finds in memory, rather than decoding
+//each one the first time it runs. Forks share the cache of the machine they were forked from, so predecoding a machine
+//once before forking it many times means the forks only decode the code the program modifies
+func (i *Intcode) Predecode() {
+	end := i.memory.size
+	if end > decodeCacheLimit {
+		end = decodeCacheLimit
+	}
+	cells := make([]int, end)
+	for addr := range cells {
+		cells[addr] = i.memory.Read(addr)
+	}
+	for _, in := range Disassemble(cells) {
+		if !in.IsData() {
+			i.memory.decodedAt(in.Addr)
+		}
+	}
+}
+
+//SetDecodeCache turns the decoded instruction cache on or off. It is on by default; with it off every instruction is
+//decoded each time it runs by HandleInstruction
+func (i *Intcode) SetDecodeCache(enabled bool) {
+	i.noDecodeCache = !enabled
+}
+
+//execute runs the instruction at pos, from the decoded instruction cache when it can. It returns the same values as
+//HandleInstruction
+func (i *Intcode) execute(pos int) (int, int, error) {
+	if i.noDecodeCache {
+		return i.HandleInstruction(pos)
+	}
+	d := i.memory.decodedAt(pos)
+	if d == nil || (d.hasRelative && d.outOfRange(i)) {
+		return i.HandleInstruction(pos)
+	}
+	next, err := d.exec(i, pos, d)
+	if err != nil && err != errNeedsInput {
+		return -1, -1, err
+	}
+	return d.opcode, next, err
+}
+
+//outOfRange returns whether any relative mode parameter is at a negative address with the current relative base
+func (d *decodedInstruction) outOfRange(i *Intcode) bool {
+	for idx := 0; idx < d.length-1; idx++ {
+		if d.relative[idx] && i.relativeBase+d.locs[idx] < 0 {
+			return true
+		}
+	}
+	return false
+}
+
+//loc returns the location of parameter idx
+func (d *decodedInstruction) loc(i *Intcode, idx int) int {
+	if d.relative[idx] {
+		return i.relativeBase + d.locs[idx]
+	}
+	return d.locs[idx]
+}
+
+func execAdd(i *Intcode, pos int, d *decodedInstruction) (int, error) {
+	a, b := i.memory.Read(d.loc(i, 0)), i.memory.Read(d.loc(i, 1))
+	if addOverflows(a, b) {
+		return -1, &OverflowError{Pos: pos, Instruction: i.memory.Read(pos), A: a, B: b}
+	}
+	i.write(d.loc(i, 2), a+b)
+	return pos + 4, nil
+}
+
+func execMul(i *Intcode, pos int, d *decodedInstruction) (int, error) {
+	a, b := i.memory.Read(d.loc(i, 0)), i.memory.Read(d.loc(i, 1))
+	if mulOverflows(a, b) {
+		return -1, &OverflowError{Pos: pos, Instruction: i.memory.Read(pos), A: a, B: b}
+	}
+	i.write(d.loc(i, 2), a*b)
+	return pos + 4, nil
+}
+
+func execInput(i *Intcode, pos int, d *decodedInstruction) (int, error) {
+	val, err := i.readInput(pos)
+	if err == errNeedsInput {
+		return pos, err
+	}
+	if err != nil {
+		return -1, err
+	}
+	i.write(d.loc(i, 0), val)
+	return pos + 2, nil
+}
+
+func execOutput(i *Intcode, pos int, d *decodedInstruction) (int, error) {
+	i.output = i.memory.Read(d.loc(i, 0))
+	if i.out != nil {
+		if err := i.out.WriteInt(i.output); err != nil {
+			return -1, errors.Wrap(err, fmt.Sprintf("io error: unable to write value %d to output", i.output))
+		}
+	}
+	return pos + 2, nil
+}
+
+func execJumpIfTrue(i *Intcode, pos int, d *decodedInstruction) (int, error) {
+	if i.memory.Read(d.loc(i, 0)) != 0 {
+		return i.memory.Read(d.loc(i, 1)), nil
+	}
+	return pos + 3, nil
+}
+
+func execJumpIfFalse(i *Intcode, pos int, d *decodedInstruction) (int, error) {
+	if i.memory.Read(d.loc(i, 0)) == 0 {
+		return i.memory.Read(d.loc(i, 1)), nil
+	}
+	return pos + 3, nil
+}
+
+func execLessThan(i *Intcode, pos int, d *decodedInstruction) (int, error) {
+	val := 0
+	if i.memory.Read(d.loc(i, 0)) < i.memory.Read(d.loc(i, 1)) {
+		val = 1
+	}
+	i.write(d.loc(i, 2), val)
+	return pos + 4, nil
+}
+
+func execEquals(i *Intcode, pos int, d *decodedInstruction) (int, error) {
+	val := 0
+	if i.memory.Read(d.loc(i, 0)) == i.memory.Read(d.loc(i, 1)) {
+		val = 1
+	}
+	i.write(d.loc(i, 2), val)
+	return pos + 4, nil
+}
+
+func execAdjustRelativeBase(i *Intcode, pos int, d *decodedInstruction) (int, error) {
+	i.relativeBase += i.memory.Read(d.loc(i, 0))
+	return pos + 2, nil
+}
+
+func execHalt(i *Intcode, pos int, d *decodedInstruction) (int, error) {
+	return pos, nil
+}
@@ -0,0 +1,104 @@
+package intcode
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDecodeCache_Invalidated(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []int
+		want    int
+	}{
+		{
+			//adds 5 to m[20], then the first time through turns the ADD into a MUL and runs it again
+			name:    "opcode",
+			program: []int{1001, 20, 5, 20, 1008, 0, 1001, 21, 1006, 21, 18, 1101, 0, 1002, 0, 1105, 1, 0, 99, 0, 1, 0},
+			want:    30,
+		},
+		{
+			//adds 5 to m[20], then the first time through changes the 5 to a 7 and runs it again
+			name:    "parameter",
+			program: []int{1001, 20, 5, 20, 1008, 2, 5, 21, 1006, 21, 18, 1101, 0, 7, 2, 1105, 1, 0, 99, 0, 1, 0},
+			want:    13,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, cached := range []bool{true, false} {
+				i := InitIO(tt.program, nil, nil)
+				i.SetDecodeCache(cached)
+				if status, err := i.Run(); err != nil || status != Halted {
+					t.Fatalf("Run() = %v, %v, want Halted", status, err)
+				}
+				if got := i.Peek(20); got != tt.want {
+					t.Errorf("with the cache set to %v, Peek(20) = %d, want %d", cached, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeCache_Fork(t *testing.T) {
+	//the self-modifying program from TestDecodeCache_Invalidated, run from forks sharing one predecoded cache
+	base := InitIO([]int{1001, 20, 5, 20, 1008, 0, 1001, 21, 1006, 21, 18, 1101, 0, 1002, 0, 1105, 1, 0, 99, 0, 1, 0}, nil, nil)
+	base.Predecode()
+	for run := 0; run < 3; run++ {
+		i := base.Fork()
+		if status, err := i.Run(); err != nil || status != Halted {
+			t.Fatalf("run %d: Run() = %v, %v, want Halted", run, status, err)
+		}
+		if got := i.Peek(20); got != 30 {
+			t.Errorf("run %d: Peek(20) = %d, want 30", run, got)
+		}
+	}
+	if status, err := base.Run(); err != nil || status != Halted || base.Peek(20) != 30 {
+		t.Errorf("base Run() = %v, %v with Peek(20) = %d, want Halted with 30", status, err, base.Peek(20))
+	}
+}
+
+//benchmarkBatch is the number of machines set up at a time by benchmarks that only time running them
+const benchmarkBatch = 1000
+
+//BenchmarkRun_Day5 runs the day-5 diagnostic program from a fork of a loaded machine, the way searches run programs
+//many times, with and without the decoded instruction cache
+func BenchmarkRun_Day5(b *testing.B) {
+	program, err := LoadIntCodeProgram("../day-5/program.txt")
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, input := range []int{1, 5} {
+		for _, cached := range []bool{false, true} {
+			name := fmt.Sprintf("input=%d/HandleInstruction", input)
+			base := InitIO(program, nil, nil)
+			base.SetDecodeCache(cached)
+			if cached {
+				name = fmt.Sprintf("input=%d/DecodeCache", input)
+				base.Predecode()
+			}
+			b.Run(name, func(b *testing.B) {
+				//the forks are made and have their page copied in batches outside of the timer, so only running the
+				//instructions is timed
+				machines := make([]*Intcode, 0, benchmarkBatch)
+				for n := 0; n < b.N; n++ {
+					if len(machines) == 0 {
+						b.StopTimer()
+						for len(machines) < benchmarkBatch {
+							i := base.Fork()
+							i.SetIO(NewSliceInput(input), &SliceOutput{})
+							i.Poke(0, i.Peek(0))
+							machines = append(machines, i)
+						}
+						b.StartTimer()
+					}
+					i := machines[len(machines)-1]
+					machines = machines[:len(machines)-1]
+					if _, err := i.Run(); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
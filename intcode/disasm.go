@@ -0,0 +1,296 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package intcode
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//Mnemonics maps every opcode to the short name used by the disassembler and assembler
+var Mnemonics = map[int]string{
+	1:  "ADD",
+	2:  "MUL",
+	3:  "IN",
+	4:  "OUT",
+	5:  "JT",
+	6:  "JF",
+	7:  "LT",
+	8:  "EQ",
+	9:  "ARB",
+	99: "HLT",
+}
+
+//writeParam is the index of the parameter each writing opcode stores its result to
+var writeParam = map[int]int{1: 2, 2: 2, 3: 0, 7: 2, 8: 2}
+
+//dataPerLine is the most data cells grouped into a single DATA entry of a listing
+const dataPerLine = 8
+
+//Instruction is a single entry of a disassembly listing. It is either a decoded instruction or a run of cells that
+//could not be reached by statically following the program from position 0, in which case Mnemonic is DATA
+type Instruction struct {
+	Addr int
+	//Cells are the raw values of the instruction and its parameters, or of the data
+	Cells    []int
+	Opcode   int
+	Mnemonic string
+	//Modes are the decoded modes of each parameter
+	Modes []int
+	//Notes flag anything the disassembler could not work out statically, such as code that is written to at runtime
+	Notes []string
+}
+
+//IsData returns whether the entry is data rather than a decoded instruction
+func (in Instruction) IsData() bool {
+	return in.Mnemonic == "DATA"
+}
+
+//Operands returns the parameters of the instruction formatted by their mode: [addr] for position mode, a bare value
+//for immediate mode and [rb+offset] for relative mode
+func (in Instruction) Operands() []string {
+	if in.IsData() {
+		operands := make([]string, len(in.Cells))
+		for idx, val := range in.Cells {
+			operands[idx] = strconv.Itoa(val)
+		}
+		return operands
+	}
+	operands := make([]string, len(in.Modes))
+	for idx, mode := range in.Modes {
+		operands[idx] = FormatOperand(in.Cells[idx+1], mode)
+	}
+	return operands
+}
+
+//FormatOperand formats a parameter value according to its mode
+func FormatOperand(val int, mode int) string {
+	switch mode {
+	case 0:
+		return fmt.Sprintf("[%d]", val)
+	case 1:
+		return strconv.Itoa(val)
+	case 2:
+		if val < 0 {
+			return fmt.Sprintf("[rb%d]", val)
+		}
+		return fmt.Sprintf("[rb+%d]", val)
+	}
+	return fmt.Sprintf("?%d", val)
+}
+
+func (in Instruction) String() string {
+	cells := make([]string, len(in.Cells))
+	for idx, val := range in.Cells {
+		cells[idx] = strconv.Itoa(val)
+	}
+	line := fmt.Sprintf("%6d: %-24s %-4s %s", in.Addr, strings.Join(cells, ","), in.Mnemonic, strings.Join(in.Operands(), ", "))
+	if len(in.Modes) > 0 {
+		modes := make([]string, len(in.Modes))
+		for idx, mode := range in.Modes {
+			modes[idx] = strconv.Itoa(mode)
+		}
+		line = fmt.Sprintf("%-64s modes %s", line, strings.Join(modes, ","))
+	}
+	if len(in.Notes) > 0 {
+		line = fmt.Sprintf("%-80s ; %s", line, strings.Join(in.Notes, "; "))
+	}
+	return strings.TrimRight(line, " ")
+}
+
+//Disassemble statically decodes a program. Starting at position 0 it follows every instruction that can be reached by
+//falling through or by a jump with an immediate target. Anything left over is decoded with a linear sweep, and cells
+//that don't decode as an instruction are listed as data. Reached cells that can't be decoded, jumps whose target is only
+//known at runtime and instructions that write into decoded code are flagged in the notes of the entries involved
+func Disassemble(program []int) []Instruction {
+	decoded := map[int]*Instruction{}
+	//stuck holds the reachable addresses that do not hold a valid instruction, usually because the program writes one
+	//there before reaching it
+	stuck := map[int]bool{}
+	work := []int{0}
+	for len(work) > 0 {
+		addr := work[len(work)-1]
+		work = work[:len(work)-1]
+		if addr < 0 || addr >= len(program) || decoded[addr] != nil || stuck[addr] {
+			continue
+		}
+		in, ok := decodeAt(program, addr)
+		if !ok {
+			stuck[addr] = true
+			continue
+		}
+		decoded[addr] = in
+		work = append(work, successors(in)...)
+	}
+
+	listing := make([]*Instruction, 0, len(decoded))
+	//owner maps each cell that is part of a listed instruction to that instruction
+	owner := map[int]*Instruction{}
+	claim := func(in *Instruction) {
+		for cell := in.Addr; cell < in.Addr+len(in.Cells); cell++ {
+			if other, ok := owner[cell]; ok && other != in {
+				in.Notes = append(in.Notes, fmt.Sprintf("overlaps instruction at %d", other.Addr))
+				continue
+			}
+			owner[cell] = in
+		}
+		listing = append(listing, in)
+	}
+	sweeping := false
+	for addr := 0; addr < len(program); {
+		if in, ok := decoded[addr]; ok {
+			claim(in)
+			addr += len(in.Cells)
+			sweeping = false
+			continue
+		}
+		if stuck[addr] {
+			claim(&Instruction{Addr: addr, Cells: program[addr : addr+1], Mnemonic: "DATA",
+				Notes: []string{"reached but cannot be decoded statically"}})
+			addr++
+			sweeping = false
+			continue
+		}
+		if in, ok := decodeAt(program, addr); ok && !claimedBetween(decoded, stuck, addr+1, addr+len(in.Cells)) {
+			if !sweeping {
+				in.Notes = append(in.Notes, "not reached statically, decoded by linear sweep")
+				sweeping = true
+			}
+			claim(in)
+			addr += len(in.Cells)
+			continue
+		}
+		start := addr
+		for addr < len(program) && addr-start < dataPerLine && decoded[addr] == nil && !stuck[addr] {
+			addr++
+			if addr >= len(program) {
+				break
+			}
+			if in, ok := decodeAt(program, addr); ok && !claimedBetween(decoded, stuck, addr+1, addr+len(in.Cells)) {
+				break
+			}
+		}
+		listing = append(listing, &Instruction{Addr: start, Cells: program[start:addr], Mnemonic: "DATA"})
+	}
+
+	//flag writes with a static target that land on code
+	for _, in := range listing {
+		idx, ok := writeParam[in.Opcode]
+		if !ok || in.IsData() {
+			continue
+		}
+		if in.Modes[idx] == 1 {
+			in.Notes = append(in.Notes, "writes to an immediate parameter")
+			continue
+		}
+		if in.Modes[idx] != 0 {
+			continue
+		}
+		target := in.Cells[idx+1]
+		if modified, ok := owner[target]; ok {
+			in.Notes = append(in.Notes, fmt.Sprintf("self-modifying: writes into code at %d", target))
+			if modified != in {
+				modified.Notes = append(modified.Notes, fmt.Sprintf("modified at runtime by instruction at %d", in.Addr))
+			}
+		}
+	}
+
+	result := make([]Instruction, len(listing))
+	for idx, in := range listing {
+		result[idx] = *in
+	}
+	return result
+}
+
+//claimedBetween returns whether any address in [from, to) was reached while following the program
+func claimedBetween(decoded map[int]*Instruction, stuck map[int]bool, from int, to int) bool {
+	for addr := from; addr < to; addr++ {
+		if decoded[addr] != nil || stuck[addr] {
+			return true
+		}
+	}
+	return false
+}
+
+//Decode decodes the instruction at addr in the machine's current memory, returning false if it is not a valid
+//instruction. A negative addr, or an instruction whose parameters would lie past the largest address, decodes as data
+func (i *Intcode) Decode(addr int) (Instruction, bool) {
+	if addr < 0 {
+		return Instruction{Addr: addr, Mnemonic: "DATA"}, false
+	}
+	window := make([]int, 0, 4)
+	for idx := 0; idx < 4 && addr+idx >= 0; idx++ {
+		window = append(window, i.memory.Read(addr+idx))
+	}
+	in, ok := decodeAt(window, 0)
+	if !ok {
+		return Instruction{Addr: addr, Cells: window[:1], Mnemonic: "DATA"}, false
+	}
+	in.Addr = addr
+	return *in, true
+}
+
+//decodeAt decodes the instruction at addr, returning false if it is not a valid instruction
+func decodeAt(program []int, addr int) (*Instruction, bool) {
+	raw := program[addr]
+	if raw < 0 {
+		return nil, false
+	}
+	opcode, par1, par2, par3 := decode(raw)
+	mnemonic, ok := Mnemonics[opcode]
+	if !ok || raw/100000 != 0 {
+		return nil, false
+	}
+	count := paramCount[opcode]
+	if addr+count >= len(program) {
+		return nil, false
+	}
+	modes := []int{par1, par2, par3}
+	for idx, mode := range modes {
+		//modes past the instruction's parameters must be left as 0
+		if mode > 2 || (idx >= count && mode != 0) {
+			return nil, false
+		}
+	}
+	in := &Instruction{
+		Addr:     addr,
+		Cells:    program[addr : addr+count+1],
+		Opcode:   opcode,
+		Mnemonic: mnemonic,
+		Modes:    modes[:count],
+	}
+	if (opcode == 5 || opcode == 6) && par2 != 1 {
+		in.Notes = append(in.Notes, "indirect jump: target is only known at runtime")
+	}
+	return in, true
+}
+
+//successors returns the addresses execution can statically be seen to continue at after the instruction
+func successors(in *Instruction) []int {
+	next := in.Addr + len(in.Cells)
+	switch in.Opcode {
+	case 99:
+		return nil
+	case 5, 6:
+		var targets []int
+		jumpsIfTrue := in.Opcode == 5
+		//a jump whose condition is immediate always or never jumps
+		condKnown := in.Modes[0] == 1
+		condTrue := in.Cells[1] != 0
+		if !condKnown || condTrue != jumpsIfTrue {
+			targets = append(targets, next)
+		}
+		if condKnown && condTrue != jumpsIfTrue {
+			return targets
+		}
+		if in.Modes[1] == 1 {
+			return append(targets, in.Cells[2])
+		}
+		return targets
+	}
+	return []int{next}
+}
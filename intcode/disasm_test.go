@@ -0,0 +1,72 @@
+package intcode
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDisassemble(t *testing.T) {
+	//reads a value, writes it over the ADD's opcode, jumps over some data, then outputs through the relative base
+	program := []int{3, 6, 1105, 1, 10, 42, 1101, 0, 0, 0, 109, 5, 204, -1, 99}
+	listing := Disassemble(program)
+
+	type entry struct {
+		addr     int
+		mnemonic string
+		operands []string
+	}
+	want := []entry{
+		{0, "IN", []string{"[6]"}},
+		{2, "JT", []string{"1", "10"}},
+		{5, "DATA", []string{"42"}},
+		{6, "ADD", []string{"0", "0", "[0]"}},
+		{10, "ARB", []string{"5"}},
+		{12, "OUT", []string{"[rb-1]"}},
+		{14, "HLT", []string{}},
+	}
+	if len(listing) != len(want) {
+		for _, in := range listing {
+			t.Log(in)
+		}
+		t.Fatalf("Disassemble() returned %d entries, want %d", len(listing), len(want))
+	}
+	for idx, w := range want {
+		in := listing[idx]
+		if in.Addr != w.addr || in.Mnemonic != w.mnemonic || !reflect.DeepEqual(in.Operands(), w.operands) {
+			t.Errorf("entry %d = %d %s %v, want %d %s %v", idx, in.Addr, in.Mnemonic, in.Operands(), w.addr, w.mnemonic, w.operands)
+		}
+	}
+	if notes := strings.Join(listing[0].Notes, ";"); !strings.Contains(notes, "writes into code at 6") {
+		t.Errorf("IN notes = %q, want the write into code flagged", notes)
+	}
+	if notes := strings.Join(listing[3].Notes, ";"); !strings.Contains(notes, "not reached statically") ||
+		!strings.Contains(notes, "modified at runtime by instruction at 0") {
+		t.Errorf("ADD notes = %q, want it flagged as swept and modified", notes)
+	}
+}
+
+func TestIntcode_Decode(t *testing.T) {
+	machine := Init([]int{1105, 1, -5, 99}, nil, nil)
+	tests := []struct {
+		name     string
+		addr     int
+		want     string
+		wantOk   bool
+		wantCell int
+	}{
+		{name: "instruction", addr: 0, want: "JT", wantOk: true, wantCell: 3},
+		{name: "halt", addr: 3, want: "HLT", wantOk: true, wantCell: 1},
+		{name: "negative address", addr: -5, want: "DATA"},
+		{name: "past the largest address", addr: maxInt - 1, want: "DATA", wantCell: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, ok := machine.Decode(tt.addr)
+			if in.Mnemonic != tt.want || ok != tt.wantOk || len(in.Cells) != tt.wantCell || in.Addr != tt.addr {
+				t.Errorf("Decode(%d) = %+v, %v, want %s at %d with %d cells, %v", tt.addr, in, ok, tt.want, tt.addr,
+					tt.wantCell, tt.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package intcode
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+)
+
+var (
+	//ErrUnknownOpcode is reported when the instruction pointer reaches a value whose opcode isn't one of Mnemonics
+	ErrUnknownOpcode = errors.New("unknown opcode")
+	//ErrInvalidMode is reported when a parameter of an instruction has a mode other than 0, 1 or 2
+	ErrInvalidMode = errors.New("invalid parameter mode")
+	//ErrWriteImmediate is reported when the parameter an instruction writes to is in immediate mode
+	ErrWriteImmediate = errors.New("write to an immediate mode parameter")
+	//ErrAddressOutOfRange is reported when an instruction refers to, or the program jumps to, a negative address
+	ErrAddressOutOfRange = errors.New("address out of range")
+	//ErrInputExhausted is reported when an input instruction runs after the input source has run out of values
+	ErrInputExhausted = errors.New("input exhausted")
+)
+
+//VMError is returned when a program does something the Intcode computer does not allow. It wraps one of the Err values
+//of this package, so errors.Is tells what went wrong, and errors.As gives access to where it happened
+type VMError struct {
+	//Err is what went wrong, one of ErrUnknownOpcode, ErrInvalidMode, ErrWriteImmediate, ErrAddressOutOfRange or
+	//ErrInputExhausted
+	Err error
+	//Pos is the instruction pointer of the failing instruction
+	Pos int
+	//Instruction is the raw value of the failing instruction, including its parameter modes
+	Instruction int
+	//Memory holds the cells of the failing instruction: the instruction itself followed by the next three cells, fewer
+	//when the instruction is at the very end of the address space
+	Memory []int
+	//Param is the index of the parameter at fault, or -1 when the fault is with the instruction as a whole
+	Param int
+	//Addr is the address that was out of range for ErrAddressOutOfRange. On the big backend it is -1 when the address
+	//doesn't fit in an int
+	Addr int
+}
+
+func (e *VMError) Error() string {
+	var what string
+	switch e.Err {
+	case ErrUnknownOpcode:
+		what = fmt.Sprintf("unknown opcode %d", e.Instruction%100)
+	case ErrInvalidMode:
+		what = fmt.Sprintf("invalid mode %d for parameter %d", paramMode(e.Instruction, e.Param), e.Param+1)
+	case ErrWriteImmediate:
+		what = fmt.Sprintf("parameter %d is written to but is in immediate mode", e.Param+1)
+	case ErrAddressOutOfRange:
+		if e.Param < 0 {
+			return fmt.Sprintf("address out of range: the instruction pointer moved to %d", e.Pos)
+		}
+		what = fmt.Sprintf("address out of range: parameter %d refers to address %d", e.Param+1, e.Addr)
+	default:
+		what = e.Err.Error()
+	}
+	return fmt.Sprintf("%s at position %d (instruction %d, memory %s)", what, e.Pos, e.Instruction, joinInts(e.Memory))
+}
+
+//Unwrap returns the Err value describing the error
+func (e *VMError) Unwrap() error {
+	return e.Err
+}
+
+//paramMode returns the mode of parameter idx of an instruction
+func paramMode(instruction int, idx int) int {
+	instruction /= 100
+	for ; idx > 0; idx-- {
+		instruction /= 10
+	}
+	return instruction % 10
+}
+
+//checkInstruction validates the opcode and parameter modes of an instruction, returning the index of the parameter at
+//fault along with the kind of error. err is nil for a valid instruction
+func checkInstruction(opcode int, modes [3]int) (param int, err error) {
+	count, ok := paramCount[opcode]
+	if !ok {
+		return -1, ErrUnknownOpcode
+	}
+	for idx := 0; idx < count; idx++ {
+		if modes[idx] > 2 {
+			return idx, ErrInvalidMode
+		}
+	}
+	if idx, ok := writeParam[opcode]; ok && modes[idx] == 1 {
+		return idx, ErrWriteImmediate
+	}
+	return -1, nil
+}
+
+//vmError builds the VMError for the instruction at pos
+func (i *Intcode) vmError(err error, pos int, param int, addr int) *VMError {
+	e := &VMError{Err: err, Pos: pos, Param: param, Addr: addr}
+	//the cells after an instruction near the highest int would wrap around to negative addresses, so stop before them
+	for addr := pos; addr >= 0 && addr < pos+4; addr++ {
+		e.Memory = append(e.Memory, i.memory.Read(addr))
+	}
+	if len(e.Memory) > 0 {
+		e.Instruction = e.Memory[0]
+	}
+	return e
+}
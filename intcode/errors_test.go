@@ -0,0 +1,125 @@
+package intcode
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestIntcode_Run_VMError(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []int
+		want    error
+		pos     int
+		param   int
+		addr    int
+	}{
+		{name: "unknown opcode", program: []int{1101, 1, 1, 5, 42, 0, 99}, want: ErrUnknownOpcode, pos: 4, param: -1},
+		{name: "invalid mode", program: []int{301, 0, 0, 0, 99}, want: ErrInvalidMode, pos: 0, param: 0},
+		{name: "invalid mode past the parameters", program: []int{30104, 0, 99}, want: nil},
+		{name: "write immediate", program: []int{11101, 1, 1, 0, 99}, want: ErrWriteImmediate, pos: 0, param: 2},
+		{name: "negative position", program: []int{1, -1, 0, 0, 99}, want: ErrAddressOutOfRange, pos: 0, param: 0, addr: -1},
+		{name: "negative relative", program: []int{109, 3, 22201, -5, 0, 0, 99}, want: ErrAddressOutOfRange, pos: 2, param: 0, addr: -2},
+		{name: "jump to negative", program: []int{1105, 1, -3}, want: ErrAddressOutOfRange, pos: -3, param: -1, addr: -3},
+		{name: "input exhausted", program: []int{3, 5, 3, 5, 99, 0}, want: ErrInputExhausted, pos: 2, param: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, run := range []struct{ cached, traced bool }{{true, false}, {false, false}, {true, true}, {false, true}} {
+				i := InitIO(tt.program, NewSliceInput(7), &SliceOutput{})
+				i.SetDecodeCache(run.cached)
+				if run.traced {
+					i.SetTracer(&TraceRecorder{})
+				}
+				setup := fmt.Sprintf("with the cache set to %v and tracing set to %v", run.cached, run.traced)
+				status, err := i.Run()
+				if tt.want == nil {
+					if err != nil || status != Halted {
+						t.Errorf("%s, Run() = %v, %v, want Halted", setup, status, err)
+					}
+					continue
+				}
+				if !errors.Is(err, tt.want) {
+					t.Fatalf("%s, Run() error = %v, want %v", setup, err, tt.want)
+				}
+				var vmErr *VMError
+				if !errors.As(err, &vmErr) {
+					t.Fatalf("%s, Run() error = %v, want a VMError", setup, err)
+				}
+				if vmErr.Pos != tt.pos || vmErr.Param != tt.param || vmErr.Addr != tt.addr {
+					t.Errorf("%s, VMError = %+v, want position %d, parameter %d, address %d",
+						setup, vmErr, tt.pos, tt.param, tt.addr)
+				}
+				if tt.pos >= 0 && (vmErr.Instruction != tt.program[tt.pos] || len(vmErr.Memory) != 4) {
+					t.Errorf("%s, VMError = %+v, want instruction %d and 4 cells of memory",
+						setup, vmErr, tt.program[tt.pos])
+				}
+			}
+		})
+	}
+}
+
+func TestVMError_Error(t *testing.T) {
+	_, err := Init([]int{1, 0, 0, 0, 1042, 7, 8, 9}, nil, nil).Run()
+	want := "unknown opcode 42 at position 4 (instruction 1042, memory 1042, 7, 8, 9)"
+	if err == nil || err.Error() != want {
+		t.Errorf("Run() error = %v, want %q", err, want)
+	}
+}
+
+func TestBigIntcode_Run_VMError(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []int
+		want    error
+	}{
+		{name: "unknown opcode", program: []int{42, 0, 0, 0}, want: ErrUnknownOpcode},
+		{name: "write immediate", program: []int{11101, 1, 1, 0, 99}, want: ErrWriteImmediate},
+		{name: "negative position", program: []int{1, -1, 0, 0, 99}, want: ErrAddressOutOfRange},
+		{name: "input exhausted", program: []int{3, 5, 99}, want: ErrInputExhausted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := InitBig(ToBig(tt.program), strings.NewReader(""), &strings.Builder{})
+			_, err := b.Run()
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Run() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	huge := new(big.Int).Lsh(big.NewInt(1), 80)
+	addressTests := []struct {
+		name    string
+		program []*big.Int
+		param   int
+	}{
+		{name: "position parameter", program: []*big.Int{big.NewInt(4), huge, big.NewInt(99)}, param: 0},
+		{name: "relative parameter", program: []*big.Int{big.NewInt(204), huge, big.NewInt(99)}, param: 0},
+		{name: "relative parameter past the base", program: []*big.Int{big.NewInt(109), big.NewInt(int64(maxInt)),
+			big.NewInt(204), big.NewInt(1), big.NewInt(99)}, param: 0},
+		{name: "jump target", program: []*big.Int{big.NewInt(1105), big.NewInt(1), huge, big.NewInt(99)}, param: 1},
+		{name: "relative base offset", program: []*big.Int{big.NewInt(109), huge, big.NewInt(99)}, param: 0},
+	}
+	for _, tt := range addressTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := InitBig(tt.program, nil, &strings.Builder{}).Run()
+			var vmErr *VMError
+			if !errors.Is(err, ErrAddressOutOfRange) || !errors.As(err, &vmErr) {
+				t.Fatalf("Run() error = %v, want a VMError wrapping %v", err, ErrAddressOutOfRange)
+			}
+			if vmErr.Param != tt.param || vmErr.Addr != -1 {
+				t.Errorf("VMError = %+v, want parameter %d and address -1", vmErr, tt.param)
+			}
+		})
+	}
+
+	b := InitBig([]*big.Int{huge}, nil, nil)
+	_, err := b.Run()
+	if err == nil || errors.Is(err, ErrUnknownOpcode) {
+		t.Errorf("Run() error = %v, want an instruction that doesn't fit in an int", err)
+	}
+}
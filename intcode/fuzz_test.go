@@ -0,0 +1,218 @@
+package intcode
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+const (
+	//fuzzSteps limits how many instructions each fuzzed program runs for, most random tapes loop forever
+	fuzzSteps = 2000
+	//fuzzTapeLimit and fuzzInputLimit cap the number of values decoded from the fuzzer's bytes
+	fuzzTapeLimit  = 256
+	fuzzInputLimit = 64
+)
+
+//errReferenceOverflow is what the reference interpreter reports for an add or multiply that overflows
+var errReferenceOverflow = errors.New("overflow")
+
+//FuzzIntcode runs random tapes and inputs on the interpreter, with the decode cache on and off and with and without a
+//tracer attached, and checks that it never panics, only fails with typed errors and behaves exactly the same as
+//referenceRun. Cases the fuzzer finds are kept in testdata/fuzz/FuzzIntcode and run as regression tests by go test
+func FuzzIntcode(f *testing.F) {
+	f.Add(encodeValues([]int{1, 0, 0, 0, 99}), encodeValues(nil))
+	f.Add(encodeValues([]int{3, 9, 8, 9, 10, 9, 4, 9, 99, -1, 8}), encodeValues([]int{8}))
+	f.Add(encodeValues([]int{109, 1, 204, -1, 1001, 100, 1, 100, 1008, 100, 16, 101, 1006, 101, 0, 99}), encodeValues(nil))
+	f.Add(encodeValues([]int{1105, 1, 7, 42, 0, 0, 0, 2101, -9, 3, 0, 1106, 0, 0}), encodeValues([]int{5}))
+	f.Fuzz(func(t *testing.T, tapeBytes []byte, inputBytes []byte) {
+		tape := decodeValues(tapeBytes, fuzzTapeLimit)
+		input := decodeValues(inputBytes, fuzzInputLimit)
+		want := referenceRun(tape, input, fuzzSteps)
+		for _, run := range []struct{ cached, traced bool }{{true, false}, {false, false}, {true, true}, {false, true}} {
+			got := fuzzRun(t, tape, input, run.cached, run.traced)
+			setup := fmt.Sprintf("with the cache set to %v and tracing set to %v, tape %v and input %v", run.cached,
+				run.traced, tape, input)
+			if got.halted != want.halted || got.err != want.err || got.pos != want.pos {
+				t.Fatalf("%s: got halted %v, error %v at position %d, want halted %v, error %v at position %d", setup,
+					got.halted, got.err, got.pos, want.halted, want.err, want.pos)
+			}
+			if !reflect.DeepEqual(got.outputs, want.outputs) {
+				t.Fatalf("%s: outputs %v, want %v", setup, got.outputs, want.outputs)
+			}
+			for addr, val := range want.mem {
+				if got.machine.Peek(addr) != val {
+					t.Fatalf("%s: Peek(%d) = %d, want %d", setup, addr, got.machine.Peek(addr), val)
+				}
+			}
+		}
+	})
+}
+
+//fuzzResult is how a run ended. err is one of the typed errors of this package, or errReferenceOverflow
+type fuzzResult struct {
+	outputs []int
+	halted  bool
+	err     error
+	pos     int
+	mem     map[int]int
+	machine *Intcode
+}
+
+//fuzzRun runs tape on the interpreter with a limit of fuzzSteps instructions, failing the test if it returns an error that
+//isn't one of the package's typed errors. A TraceRecorder is attached when traced is set
+func fuzzRun(t *testing.T, tape []int, input []int, cached bool, traced bool) fuzzResult {
+	out := &SliceOutput{}
+	machine := InitIO(tape, NewSliceInput(input...), out)
+	machine.SetDecodeCache(cached)
+	if traced {
+		machine.SetTracer(&TraceRecorder{})
+	}
+	machine.SetLimits(Limits{MaxSteps: fuzzSteps})
+	res := fuzzResult{machine: machine}
+	status, err := machine.Run()
+	res.pos, res.outputs = machine.Pos(), out.Values
+	var vmErr *VMError
+	var overflow *OverflowError
+	switch {
+	case err == nil:
+		res.halted = status == Halted
+	case errors.Is(err, ErrStepLimit):
+	case errors.As(err, &vmErr):
+		res.err, res.pos = vmErr.Err, vmErr.Pos
+	case errors.As(err, &overflow):
+		res.err, res.pos = errReferenceOverflow, overflow.Pos
+	default:
+		t.Fatalf("tape %v with input %v failed with an untyped error: %v", tape, input, err)
+	}
+	return res
+}
+
+//referenceRun is a deliberately simple interpreter written straight from the puzzle descriptions, with the checks of
+//VMError added, for the interpreter to be compared against
+func referenceRun(tape []int, input []int, maxSteps int) fuzzResult {
+	mem := map[int]int{}
+	for addr, val := range tape {
+		mem[addr] = val
+	}
+	res := fuzzResult{mem: mem}
+	pos, base := 0, 0
+	params := map[int]int{1: 3, 2: 3, 3: 1, 4: 1, 5: 2, 6: 2, 7: 3, 8: 3, 9: 1}
+	for steps := 0; steps < maxSteps; steps++ {
+		res.pos = pos
+		if pos < 0 {
+			res.err = ErrAddressOutOfRange
+			return res
+		}
+		instruction := mem[pos]
+		opcode := instruction % 100
+		if opcode == 99 {
+			res.halted = true
+			return res
+		}
+		count, ok := params[opcode]
+		if !ok {
+			res.err = ErrUnknownOpcode
+			return res
+		}
+		var locs, modes [3]int
+		for p := 0; p < count; p++ {
+			modes[p] = instruction / 100
+			for d := 0; d < p; d++ {
+				modes[p] /= 10
+			}
+			modes[p] %= 10
+			switch modes[p] {
+			case 0:
+				locs[p] = mem[pos+1+p]
+			case 1:
+				locs[p] = pos + 1 + p
+			case 2:
+				locs[p] = base + mem[pos+1+p]
+			default:
+				res.err = ErrInvalidMode
+				return res
+			}
+		}
+		//the written parameter is always the last one
+		writes := opcode == 1 || opcode == 2 || opcode == 3 || opcode == 7 || opcode == 8
+		if writes && modes[count-1] == 1 {
+			res.err = ErrWriteImmediate
+			return res
+		}
+		for p := 0; p < count; p++ {
+			if pos+1+p < 0 || locs[p] < 0 {
+				res.err = ErrAddressOutOfRange
+				return res
+			}
+		}
+		a, b := mem[locs[0]], mem[locs[1]]
+		switch opcode {
+		case 1, 2:
+			if (opcode == 1 && addOverflows(a, b)) || (opcode == 2 && mulOverflows(a, b)) {
+				res.err = errReferenceOverflow
+				return res
+			}
+			if opcode == 1 {
+				mem[locs[2]] = a + b
+			} else {
+				mem[locs[2]] = a * b
+			}
+		case 3:
+			if len(input) == 0 {
+				res.err = ErrInputExhausted
+				return res
+			}
+			mem[locs[0]] = input[0]
+			input = input[1:]
+		case 4:
+			res.outputs = append(res.outputs, a)
+		case 5:
+			if a != 0 {
+				pos = b - count - 1
+			}
+		case 6:
+			if a == 0 {
+				pos = b - count - 1
+			}
+		case 7, 8:
+			val := 0
+			if (opcode == 7 && a < b) || (opcode == 8 && a == b) {
+				val = 1
+			}
+			mem[locs[2]] = val
+		case 9:
+			base += a
+		}
+		pos += count + 1
+	}
+	res.pos = pos
+	return res
+}
+
+//encodeValues encodes values the way decodeValues reads them back
+func encodeValues(values []int) []byte {
+	var buf []byte
+	for _, val := range values {
+		buf = append(buf, make([]byte, binary.MaxVarintLen64)...)
+		n := binary.PutVarint(buf[len(buf)-binary.MaxVarintLen64:], int64(val))
+		buf = buf[:len(buf)-binary.MaxVarintLen64+n]
+	}
+	return buf
+}
+
+//decodeValues reads up to limit varint encoded values from data, stopping at the first one that doesn't decode
+func decodeValues(data []byte, limit int) []int {
+	var values []int
+	for len(data) > 0 && len(values) < limit {
+		val, n := binary.Varint(data)
+		if n <= 0 {
+			break
+		}
+		values = append(values, int(val))
+		data = data[n:]
+	}
+	return values
+}
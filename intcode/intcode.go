@@ -0,0 +1,405 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2019-12-27
+// Author:   matt
+// Project:  aoc-2019
+
+//Package intcode implements the Intcode computer used by several of the 2019 puzzles.
+package intcode
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+)
+
+//Intcode is a single Intcode machine: a loaded program along with where it reads input from and writes output to
+type Intcode struct {
+	memory       *Memory
+	pos          int
+	relativeBase int
+	halted       bool
+	in           InputSource
+	out          OutputSink
+	pending      []int
+	output       int
+	steps        int
+	tracer       Tracer
+	writes       []MemoryWrite
+	profile      *Profile
+	limits       *Limits
+	loops        *loopTracker
+	//noDecodeCache turns off the decoded instruction cache, see SetDecodeCache
+	noDecodeCache bool
+}
+
+//Status describes why a machine stopped executing
+type Status int
+
+const (
+	//Running means the machine can keep executing instructions
+	Running Status = iota
+	//NeedsInput means the machine is paused on an input instruction with no input available. Supply a value with
+	//Input and resume it with Run
+	NeedsInput
+	//HasOutput means the machine produced an output value with no OutputSink connected. Read the value with Output and
+	//resume it with Run
+	HasOutput
+	//Halted means the machine executed opcode 99 and will not run any further
+	Halted
+)
+
+func (s Status) String() string {
+	switch s {
+	case Running:
+		return "Running"
+	case NeedsInput:
+		return "NeedsInput"
+	case HasOutput:
+		return "HasOutput"
+	case Halted:
+		return "Halted"
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+//errNeedsInput is returned by HandleInstruction when it reaches an input instruction with no input available
+var errNeedsInput = errors.New("no input available")
+
+//Init creates a new Intcode machine that will run the passed in program, reading whitespace or comma separated integers
+//from in and writing each output value to out on its own line. The program is copied into the machine's memory, so the
+//passed in slice is never modified by running the machine
+func Init(program []int, in io.Reader, out io.Writer) *Intcode {
+	var source InputSource
+	if in != nil {
+		source = TextInput(in)
+	}
+	var sink OutputSink
+	if out != nil {
+		sink = TextOutput(out)
+	}
+	return InitIO(program, source, sink)
+}
+
+//InitIO creates a new Intcode machine that will run the passed in program, reading input values from in and sending
+//output values to out. Either can be nil, in which case the machine pauses whenever it needs input or produces output
+//so the caller can handle it
+func InitIO(program []int, in InputSource, out OutputSink) *Intcode {
+	return &Intcode{
+		memory: NewMemory(program),
+		in:     in,
+		out:    out,
+	}
+}
+
+//Backend selects how the memory cells of a machine are stored
+type Backend int
+
+const (
+	//IntBackend stores memory cells as Go ints. It is the fastest backend and reports an OverflowError instead of
+	//silently wrapping when arithmetic does not fit in an int
+	IntBackend Backend = iota
+	//BigBackend stores memory cells as arbitrary precision integers
+	BigBackend
+)
+
+//Machine is an Intcode machine running on any of the backends
+type Machine interface {
+	Run() (Status, error)
+}
+
+//New creates a machine running the passed in program on the selected backend
+func New(program []int, in io.Reader, out io.Writer, backend Backend) Machine {
+	if backend == BigBackend {
+		return InitBig(ToBig(program), in, out)
+	}
+	return Init(program, in, out)
+}
+
+//Run executes the program from the current instruction pointer until it halts, needs input that isn't available or
+//produces output with no OutputSink connected. The instruction pointer, relative base and memory are all kept inside of
+//the machine, so calling Run again resumes execution where it left off. The returned status is only meaningful when
+//the error is nil. Run stops with a LimitError once the machine exceeds the limits set with SetLimits, see RunContext to
+//also stop it at a deadline
+func (i *Intcode) Run() (Status, error) {
+	for {
+		status, err := i.Step()
+		if err != nil || status != Running {
+			return status, err
+		}
+	}
+}
+
+//Step executes a single instruction and reports the status of the machine after it
+func (i *Intcode) Step() (Status, error) {
+	if i.halted {
+		return Halted, nil
+	}
+	if i.limits != nil {
+		if err := i.checkLimits(); err != nil {
+			return Running, err
+		}
+	}
+	var event TraceEvent
+	if i.tracer != nil {
+		event = i.startTrace()
+	}
+	opcode, pos, err := i.execute(i.pos)
+	if err == errNeedsInput {
+		return NeedsInput, nil
+	}
+	if _, ok := err.(*VMError); ok {
+		//VMErrors already say where they happened
+		return Running, err
+	}
+	if err != nil {
+		return Running, errors.Wrap(err, fmt.Sprintf("error encountered at position %d", i.pos))
+	}
+	i.steps++
+	if i.profile != nil {
+		i.profile.record(i.pos, opcode, pos)
+	}
+	if i.loops != nil {
+		i.loops.record(i.pos, opcode, pos)
+	}
+	if i.tracer != nil {
+		if err := i.finishTrace(event, opcode); err != nil {
+			return Running, errors.Wrap(err, fmt.Sprintf("unable to trace the instruction at position %d", i.pos))
+		}
+	}
+	i.pos = pos
+	switch {
+	case opcode == 99:
+		i.halted = true
+		return Halted, nil
+	case opcode == 4 && i.out == nil:
+		return HasOutput, nil
+	}
+	return Running, nil
+}
+
+//Fork returns a copy of the machine in exactly the same state. Memory is shared between the two with copy-on-write
+//semantics, so forking only costs as much as the pages that either machine goes on to write. The fork has no input
+//source, output sink, tracer or profile attached; connect it with SetIO or drive it with Input and Output. Limits set
+//with SetLimits are kept, and as the fork also keeps the step count it has only the steps the original had left
+func (i *Intcode) Fork() *Intcode {
+	fork := &Intcode{
+		memory:        i.memory.Fork(),
+		pos:           i.pos,
+		relativeBase:  i.relativeBase,
+		halted:        i.halted,
+		steps:         i.steps,
+		pending:       append([]int(nil), i.pending...),
+		output:        i.output,
+		noDecodeCache: i.noDecodeCache,
+	}
+	if i.limits != nil {
+		fork.SetLimits(*i.limits)
+	}
+	return fork
+}
+
+//SetIO connects the machine to a new input source and output sink. Either can be nil to have the machine pause instead
+func (i *Intcode) SetIO(in InputSource, out OutputSink) {
+	i.in = in
+	i.out = out
+}
+
+//Input queues values to be read by input instructions. Queued values are used before any connected InputSource
+func (i *Intcode) Input(vals ...int) {
+	i.pending = append(i.pending, vals...)
+}
+
+//Output returns the most recent value produced by an output instruction
+func (i *Intcode) Output() int {
+	return i.output
+}
+
+//Pos returns the instruction pointer
+func (i *Intcode) Pos() int {
+	return i.pos
+}
+
+//Steps returns the number of instructions the machine has executed
+func (i *Intcode) Steps() int {
+	return i.steps
+}
+
+//RelativeBase returns the current relative base used by relative mode parameters
+func (i *Intcode) RelativeBase() int {
+	return i.relativeBase
+}
+
+//Peek returns the value stored at the passed in address of the machine's memory
+func (i *Intcode) Peek(addr int) int {
+	return i.memory.Read(addr)
+}
+
+//Poke stores the passed in value at the passed in address of the machine's memory
+func (i *Intcode) Poke(addr int, val int) {
+	i.memory.Write(addr, val)
+}
+
+//Memory returns the machine's memory
+func (i *Intcode) Memory() *Memory {
+	return i.memory
+}
+
+//HandleInstruction handles a single opcode instruction. Takes in the current position and the current opcode.
+//It will return the opcode that was processed and the new position of the program. When the instruction is an input
+//with no input available, nothing is executed and the passed in position is returned along with errNeedsInput
+func (i *Intcode) HandleInstruction(pos int) (int, int, error) {
+	if pos < 0 {
+		return -1, -1, i.vmError(ErrAddressOutOfRange, pos, -1, pos)
+	}
+	instruction := i.memory.Read(pos)
+	if instruction == 99 {
+		return 99, pos, nil
+	}
+	opcode, par1, par2, par3 := decode(instruction)
+	modes := [3]int{par1, par2, par3}
+	if param, err := checkInstruction(opcode, modes); err != nil {
+		return -1, -1, i.vmError(err, pos, param, 0)
+	}
+
+	//only resolve the parameters the opcode actually takes, the values after them could be anything
+	var locs [3]int
+	for p := 0; p < paramCount[opcode]; p++ {
+		if pos+1+p < 0 {
+			//the parameter would be past the highest address
+			return -1, -1, i.vmError(ErrAddressOutOfRange, pos, p, pos+1+p)
+		}
+		locs[p] = i.paramLocation(pos+1+p, modes[p])
+		if locs[p] < 0 {
+			return -1, -1, i.vmError(ErrAddressOutOfRange, pos, p, locs[p])
+		}
+	}
+	loc1, loc2, loc3 := locs[0], locs[1], locs[2]
+	switch opcode {
+	case 1:
+		a, b := i.memory.Read(loc1), i.memory.Read(loc2)
+		if addOverflows(a, b) {
+			return -1, -1, &OverflowError{Pos: pos, Instruction: instruction, A: a, B: b}
+		}
+		i.write(loc3, a+b)
+		pos += 4
+	case 2:
+		a, b := i.memory.Read(loc1), i.memory.Read(loc2)
+		if mulOverflows(a, b) {
+			return -1, -1, &OverflowError{Pos: pos, Instruction: instruction, A: a, B: b}
+		}
+		i.write(loc3, a*b)
+		pos += 4
+	case 3:
+		//takes a single integer as input and saves it to the position given by its only parameter
+		val, err := i.readInput(pos)
+		if err == errNeedsInput {
+			return opcode, pos, err
+		}
+		if err != nil {
+			return -1, -1, err
+		}
+		i.write(loc1, val)
+		pos += 2
+	case 4:
+		//outputs the value of its only parameter
+		i.output = i.memory.Read(loc1)
+		if i.out != nil {
+			err := i.out.WriteInt(i.output)
+			if err != nil {
+				return -1, -1, errors.Wrap(err, fmt.Sprintf("io error: unable to write value %d to output", i.output))
+			}
+		}
+		pos += 2
+	case 5:
+		//jump-if-true: if first param is non-zero, sets instruction pointer to value at second parameter. Otherwise does nothing
+		pos += 3
+		if i.memory.Read(loc1) != 0 {
+			pos = i.memory.Read(loc2)
+		}
+	case 6:
+		//jump-if-false: if first param is zero, sets instruction pointer to value at second parameter. Otherwise, does nothing
+		pos += 3
+		if i.memory.Read(loc1) == 0 {
+			pos = i.memory.Read(loc2)
+		}
+	case 7:
+		//less than: if the first param is less than the second param, it stores 1 in the position given by the third parameter. Otherwise, stores 0
+		valToStore := 0
+		if i.memory.Read(loc1) < i.memory.Read(loc2) {
+			valToStore = 1
+		}
+		i.write(loc3, valToStore)
+		pos += 4
+	case 8:
+		//equals: if first param is equal to second param, store 1 at position given by third parameter. Otherwise, store 0
+		valToStore := 0
+		if i.memory.Read(loc1) == i.memory.Read(loc2) {
+			valToStore = 1
+		}
+		i.write(loc3, valToStore)
+		pos += 4
+	case 9:
+		//relative base offset: adjusts the relative base by the value of its only parameter
+		i.relativeBase += i.memory.Read(loc1)
+		pos += 2
+	}
+	return opcode, pos, nil
+}
+
+//readInput returns the value for the input instruction at pos, from the pending queue first and then from the input
+//source. It returns errNeedsInput when neither has a value
+func (i *Intcode) readInput(pos int) (int, error) {
+	switch {
+	case len(i.pending) > 0:
+		val := i.pending[0]
+		i.pending = i.pending[1:]
+		return val, nil
+	case i.in != nil:
+		val, err := i.in.ReadInt()
+		if err == io.EOF {
+			return 0, i.vmError(ErrInputExhausted, pos, -1, 0)
+		}
+		if err != nil {
+			return 0, errors.Wrap(err, fmt.Sprintf("error reading input at position %d", pos))
+		}
+		return val, nil
+	}
+	return 0, errNeedsInput
+}
+
+//paramCount is the number of parameters taken by each opcode
+var paramCount = map[int]int{1: 3, 2: 3, 3: 1, 4: 1, 5: 2, 6: 2, 7: 3, 8: 3, 9: 1, 99: 0}
+
+//write stores val at addr, recording the write when the machine is being traced
+func (i *Intcode) write(addr int, val int) {
+	i.memory.Write(addr, val)
+	if i.tracer != nil {
+		i.writes = append(i.writes, MemoryWrite{Addr: addr, Value: val})
+	}
+}
+
+//decode splits an instruction into its two digit opcode and the modes of its three parameters
+func decode(instruction int) (opcode int, par1 int, par2 int, par3 int) {
+	opcode = instruction % 100
+	instruction /= 100
+	par1 = instruction % 10
+	instruction /= 10
+	par2 = instruction % 10
+	instruction /= 10
+	par3 = instruction % 10
+	return opcode, par1, par2, par3
+}
+
+//paramLocation resolves the memory location of the parameter stored at addr according to its parameter mode.
+//Position mode (0) uses the parameter as an address, immediate mode (1) uses the parameter itself and relative mode (2)
+//uses the parameter as an offset from the relative base.
+func (i *Intcode) paramLocation(addr int, mode int) int {
+	switch mode {
+	case 1:
+		return addr
+	case 2:
+		return i.relativeBase + i.memory.Read(addr)
+	default:
+		return i.memory.Read(addr)
+	}
+}
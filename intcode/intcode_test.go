@@ -1,4 +1,4 @@
-package main
+package intcode
 
 import (
 	"bytes"
@@ -177,15 +177,81 @@ func TestIntcode_Run(t *testing.T) {
 			wantErr: false,
 			outWant: "1001\n",
 		},
+		{
+			name: "d9_p1_large_multiply",
+			fields: fields{
+				program: []int{1102, 34915192, 34915192, 7, 4, 7, 99, 0},
+				in:      strings.NewReader(""),
+				out:     &b,
+			},
+			wantErr: false,
+			outWant: "1219070632396864\n",
+		},
+		{
+			name: "d9_p1_large_immediate",
+			fields: fields{
+				program: []int{104, 1125899906842624, 99},
+				in:      strings.NewReader(""),
+				out:     &b,
+			},
+			wantErr: false,
+			outWant: "1125899906842624\n",
+		},
+		{
+			name: "relative_mode_read",
+			fields: fields{
+				program: []int{109, 5, 204, 1, 99, 0, 42},
+				in:      strings.NewReader(""),
+				out:     &b,
+			},
+			wantErr: false,
+			outWant: "42\n",
+		},
+		{
+			name: "relative_mode_write",
+			fields: fields{
+				program: []int{109, 10, 21101, 2, 3, 0, 204, 0, 99, 0, 0},
+				in:      strings.NewReader(""),
+				out:     &b,
+			},
+			wantErr: false,
+			outWant: "5\n",
+		},
+		{
+			name: "relative_mode_input_negative_base",
+			fields: fields{
+				program: []int{109, 12, 109, -3, 203, 0, 204, 0, 99, 0},
+				in:      strings.NewReader("17"),
+				out:     &b,
+			},
+			wantErr: false,
+			outWant: "17\n",
+		},
+		{
+			name: "d9_p1_quine",
+			fields: fields{
+				program: []int{109, 1, 204, -1, 1001, 100, 1, 100, 1008, 100, 16, 101, 1006, 101, 0, 99},
+				in:      strings.NewReader(""),
+				out:     &b,
+			},
+			wantErr: false,
+			outWant: "109\n1\n204\n-1\n1001\n100\n1\n100\n1008\n100\n16\n101\n1006\n101\n0\n99\n",
+		},
+		{
+			name: "write_far_past_program",
+			fields: fields{
+				program: []int{1101, 3, 4, 1000000000, 4, 1000000000, 4, 999999999, 99},
+				in:      strings.NewReader(""),
+				out:     &b,
+			},
+			wantErr: false,
+			outWant: "7\n0\n",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			i := &Intcode{
-				program: tt.fields.program,
-				in:      tt.fields.in,
-				out:     tt.fields.out,
-			}
-			if err := i.Run(); (err != nil) != tt.wantErr {
+			i := Init(tt.fields.program, tt.fields.in, tt.fields.out)
+			if _, err := i.Run(); (err != nil) != tt.wantErr {
 				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			if output := b.String(); output != tt.outWant {
@@ -195,3 +261,82 @@ func TestIntcode_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestIntcode_Run_Memory(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []int
+		want    []int
+	}{
+		{
+			name:    "d2_p1_example",
+			program: []int{1, 9, 10, 3, 2, 3, 11, 0, 99, 30, 40, 50},
+			want:    []int{3500, 9, 10, 70, 2, 3, 11, 0, 99, 30, 40, 50},
+		},
+		{
+			name:    "d2_p1_add",
+			program: []int{1, 0, 0, 0, 99},
+			want:    []int{2, 0, 0, 0, 99},
+		},
+		{
+			name:    "d2_p1_mul",
+			program: []int{2, 3, 0, 3, 99},
+			want:    []int{2, 3, 0, 6, 99},
+		},
+		{
+			name:    "d2_p1_mul_past_halt",
+			program: []int{2, 4, 4, 5, 99, 0},
+			want:    []int{2, 4, 4, 5, 99, 9801},
+		},
+		{
+			name:    "d2_p1_self_modifying",
+			program: []int{1, 1, 1, 4, 99, 5, 6, 0, 99},
+			want:    []int{30, 1, 1, 4, 2, 5, 6, 0, 99},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := Init(tt.program, nil, nil)
+			if _, err := i.Run(); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			for addr, want := range tt.want {
+				if got := i.Peek(addr); got != want {
+					t.Errorf("Peek(%d) = %d, want %d", addr, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestIntcode_Run_Resumable(t *testing.T) {
+	//doubles every input until it is given a 0
+	i := InitIO([]int{3, 13, 102, 2, 13, 13, 4, 13, 1005, 13, 0, 99, 0, 0}, nil, nil)
+	expect := func(want Status) {
+		t.Helper()
+		status, err := i.Run()
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if status != want {
+			t.Fatalf("Run() status = %v, want %v", status, want)
+		}
+	}
+
+	expect(NeedsInput)
+	if got := i.Pos(); got != 0 {
+		t.Errorf("Pos() while waiting for input = %d, want 0", got)
+	}
+	for _, val := range []int{5, -4} {
+		i.Input(val)
+		expect(HasOutput)
+		if got := i.Output(); got != val*2 {
+			t.Errorf("Output() = %d, want %d", got, val*2)
+		}
+		expect(NeedsInput)
+	}
+	i.Input(0)
+	expect(HasOutput)
+	expect(Halted)
+	expect(Halted)
+}
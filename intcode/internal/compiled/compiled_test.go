@@ -0,0 +1,126 @@
+package compiled
+
+import (
+	"bytes"
+	"go/format"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/mjourard/aoc-2019/intcode"
+)
+
+func TestGenerated(t *testing.T) {
+	//the generated files must match what Compile produces now, run go generate when this fails. Both are formatted by
+	//the same version of gofmt first, so that a change to gofmt alone doesn't make the files out of date
+	tests := []struct {
+		program string
+		name    string
+		file    string
+	}{
+		{"testdata/sum.txt", "Sum", "sum.go"},
+		{"testdata/modify.txt", "Modify", "modify.go"},
+	}
+	for _, tt := range tests {
+		program, err := intcode.LoadIntCodeProgram(tt.program)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := intcode.Compile(&buf, program, "compiled", tt.name); err != nil {
+			t.Fatalf("Compile(%s) error = %v", tt.program, err)
+		}
+		existing, err := ioutil.ReadFile(tt.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		existing, err = format.Source(existing)
+		if err != nil {
+			t.Fatalf("%s doesn't parse: %v", tt.file, err)
+		}
+		if !bytes.Equal(buf.Bytes(), existing) {
+			t.Errorf("%s is out of date with Compile, run go generate", tt.file)
+		}
+	}
+}
+
+func TestSum(t *testing.T) {
+	out := &intcode.SliceOutput{}
+	machine, status, err := Sum(intcode.NewSliceInput(10), out)
+	if err != nil || status != intcode.Halted {
+		t.Fatalf("Sum() = %v, %v, want Halted", status, err)
+	}
+	interpreted := intcode.InitIO(SumProgram, intcode.NewSliceInput(10), &intcode.SliceOutput{})
+	interpreted.Run()
+
+	if !reflect.DeepEqual(out.Values, []int{55}) {
+		t.Errorf("Sum() output = %v, want [55]", out.Values)
+	}
+	if machine.Steps() != interpreted.Steps() {
+		t.Errorf("Sum() executed %d instructions, the interpreter executed %d", machine.Steps(), interpreted.Steps())
+	}
+	if machine.Pos() != interpreted.Pos() || machine.Memory().Size() != interpreted.Memory().Size() {
+		t.Errorf("Sum() left the machine at %d with %d cells, the interpreter left it at %d with %d cells",
+			machine.Pos(), machine.Memory().Size(), interpreted.Pos(), interpreted.Memory().Size())
+	}
+}
+
+func TestSum_Pauses(t *testing.T) {
+	//with nothing to read from or write to, the compiled code hands over to the interpreter at the same points it pauses
+	machine, status, err := Sum(nil, nil)
+	if err != nil || status != intcode.NeedsInput {
+		t.Fatalf("Sum() = %v, %v, want NeedsInput", status, err)
+	}
+	machine.Input(4)
+	if status, err := machine.Run(); err != nil || status != intcode.HasOutput || machine.Output() != 10 {
+		t.Fatalf("Run() = %v, %v with output %d, want HasOutput with 10", status, err, machine.Output())
+	}
+
+	machine, status, err = Sum(intcode.NewSliceInput(4), nil)
+	if err != nil || status != intcode.HasOutput || machine.Output() != 10 {
+		t.Fatalf("Sum() = %v, %v with output %d, want HasOutput with 10", status, err, machine.Output())
+	}
+	if status, err := machine.Run(); err != nil || status != intcode.Halted {
+		t.Errorf("Run() = %v, %v, want Halted", status, err)
+	}
+}
+
+func TestModify(t *testing.T) {
+	//the program turns one of its own instructions from an ADD into a MUL and runs it again, so the compiled code has to
+	//hand over to the interpreter for the modified instruction
+	machine, status, err := Modify(nil, nil)
+	if err != nil || status != intcode.Halted {
+		t.Fatalf("Modify() = %v, %v, want Halted", status, err)
+	}
+	interpreted := intcode.InitIO(ModifyProgram, nil, nil)
+	if status, err := interpreted.Run(); err != nil || status != intcode.Halted {
+		t.Fatalf("Run() = %v, %v, want Halted", status, err)
+	}
+	if machine.Peek(20) != 30 || machine.Peek(20) != interpreted.Peek(20) {
+		t.Errorf("Modify() left %d in m[20], the interpreter left %d, want 30", machine.Peek(20), interpreted.Peek(20))
+	}
+	if machine.Steps() != interpreted.Steps() || machine.Pos() != interpreted.Pos() {
+		t.Errorf("Modify() executed %d instructions ending at %d, the interpreter executed %d ending at %d",
+			machine.Steps(), machine.Pos(), interpreted.Steps(), interpreted.Pos())
+	}
+}
+
+//benchmarkSumInput is the n that the sum benchmarks add up to, enough for the loop to dominate
+const benchmarkSumInput = 100000
+
+func BenchmarkSum_Compiled(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		if _, _, err := Sum(intcode.NewSliceInput(benchmarkSumInput), &intcode.SliceOutput{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSum_Interpreted(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		machine := intcode.InitIO(SumProgram, intcode.NewSliceInput(benchmarkSumInput), &intcode.SliceOutput{})
+		if _, err := machine.Run(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
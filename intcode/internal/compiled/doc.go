@@ -0,0 +1,11 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+//Package compiled holds programs compiled to Go by intcode.Compile, used to check the compiler's output against the
+//interpreter and to benchmark the two against each other
+package compiled
+
+//go:generate go run ../../../cmd/compile testdata/sum.txt compiled Sum sum.go
+//go:generate go run ../../../cmd/compile testdata/modify.txt compiled Modify modify.go
@@ -0,0 +1,74 @@
+// Code generated by intcode.Compile. DO NOT EDIT.
+
+package compiled
+
+import "github.com/mjourard/aoc-2019/intcode"
+
+// ModifyProgram is the program Modify was compiled from
+var ModifyProgram = []int{1001, 20, 5, 20, 1008, 0, 1001, 21, 1006, 21, 18, 1101, 0, 1002, 0, 1105, 1, 0, 99, 0, 1, 0}
+
+// ModifyCode marks the cells of ModifyProgram that hold compiled instructions
+var ModifyCode = []bool{
+	true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true,
+	true, true, true, false, false, false,
+}
+
+// Modify runs ModifyProgram as native Go code. It returns the machine left behind along with the same status and error that
+// Intcode.Run would return
+func Modify(in intcode.InputSource, out intcode.OutputSink) (*intcode.Intcode, intcode.Status, error) {
+	s := intcode.NewNativeState(ModifyProgram, in, out)
+	pc := 0
+	for {
+		switch pc {
+		case 0:
+			// ADD [20], 5, [20]
+			v, ok := s.Add(s.Load(20), 5)
+			if !ok {
+				return s.Fallback(0)
+			}
+			s.Store(20, v)
+			s.Steps++
+			fallthrough
+		case 4:
+			// EQ [0], 1001, [21]
+			v := 0
+			if s.Load(0) == 1001 {
+				v = 1
+			}
+			s.Store(21, v)
+			s.Steps++
+			fallthrough
+		case 8:
+			// JF [21], 18
+			s.Steps++
+			if s.Load(21) == 0 {
+				pc = 18
+				continue
+			}
+			fallthrough
+		case 11:
+			// ADD 0, 1002, [0]
+			v, ok := s.Add(0, 1002)
+			if !ok {
+				return s.Fallback(11)
+			}
+			s.Store(0, v)
+			s.Steps++
+			return s.Fallback(15)
+		case 15:
+			// JT 1, 0
+			s.Steps++
+			if 1 != 0 {
+				pc = 0
+				continue
+			}
+			fallthrough
+		case 18:
+			// HLT
+			s.Steps++
+			return s.Halt(18)
+		default:
+			return s.Fallback(pc)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Code generated by intcode.Compile. DO NOT EDIT.
+
+package compiled
+
+import "github.com/mjourard/aoc-2019/intcode"
+
+// SumProgram is the program Sum was compiled from
+var SumProgram = []int{3, 100, 1, 101, 100, 101, 1001, 100, -1, 100, 1005, 100, 2, 4, 101, 99}
+
+// SumCode marks the cells of SumProgram that hold compiled instructions
+var SumCode = []bool{
+	true, true, true, true, true, true, true, true, true, true, true, true, true, true, true, true,
+}
+
+// Sum runs SumProgram as native Go code. It returns the machine left behind along with the same status and error that
+// Intcode.Run would return
+func Sum(in intcode.InputSource, out intcode.OutputSink) (*intcode.Intcode, intcode.Status, error) {
+	s := intcode.NewNativeState(SumProgram, in, out)
+	pc := 0
+	for {
+		switch pc {
+		case 0:
+			// IN [100]
+			if s.In == nil {
+				return s.Fallback(0)
+			}
+			v, err := s.Input(0)
+			if err != nil {
+				return s.Fail(0, err)
+			}
+			s.Store(100, v)
+			s.Steps++
+			fallthrough
+		case 2:
+			// ADD [101], [100], [101]
+			v, ok := s.Add(s.Load(101), s.Load(100))
+			if !ok {
+				return s.Fallback(2)
+			}
+			s.Store(101, v)
+			s.Steps++
+			fallthrough
+		case 6:
+			// ADD [100], -1, [100]
+			v, ok := s.Add(s.Load(100), -1)
+			if !ok {
+				return s.Fallback(6)
+			}
+			s.Store(100, v)
+			s.Steps++
+			fallthrough
+		case 10:
+			// JT [100], 2
+			s.Steps++
+			if s.Load(100) != 0 {
+				pc = 2
+				continue
+			}
+			fallthrough
+		case 13:
+			// OUT [101]
+			if s.Out == nil {
+				s.Output = s.Load(101)
+				s.Steps++
+				return s.Pause(15)
+			}
+			if err := s.Emit(s.Load(101)); err != nil {
+				return s.Fail(13, err)
+			}
+			s.Steps++
+			fallthrough
+		case 15:
+			// HLT
+			s.Steps++
+			return s.Halt(15)
+		default:
+			return s.Fallback(pc)
+		}
+	}
+}
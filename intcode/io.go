@@ -0,0 +1,151 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package intcode
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"strconv"
+)
+
+//InputSource provides the values read by input instructions (opcode 3)
+type InputSource interface {
+	//ReadInt returns the next input value. It returns io.EOF once no more values will ever be available
+	ReadInt() (int, error)
+}
+
+//OutputSink receives the values written by output instructions (opcode 4)
+type OutputSink interface {
+	WriteInt(val int) error
+}
+
+//TextInput reads whitespace or comma separated integers from a text stream. Any number of values can arrive in a single
+//read of the underlying reader
+func TextInput(r io.Reader) InputSource {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(scanValues)
+	return &textInput{scanner: scanner}
+}
+
+type textInput struct {
+	scanner *bufio.Scanner
+}
+
+func (t *textInput) ReadInt() (int, error) {
+	if !t.scanner.Scan() {
+		if err := t.scanner.Err(); err != nil {
+			return 0, err
+		}
+		return 0, io.EOF
+	}
+	val, err := strconv.Atoi(t.scanner.Text())
+	if err != nil {
+		return 0, errors.New(fmt.Sprintf("unable to parse an integer from input '%s'", t.scanner.Text()))
+	}
+	return val, nil
+}
+
+//scanValues is a bufio.SplitFunc that splits on any run of whitespace and commas
+func scanValues(data []byte, atEOF bool) (int, []byte, error) {
+	start := 0
+	for start < len(data) && isSeparator(data[start]) {
+		start++
+	}
+	for idx := start; idx < len(data); idx++ {
+		if isSeparator(data[idx]) {
+			return idx + 1, data[start:idx], nil
+		}
+	}
+	if atEOF && len(data) > start {
+		return len(data), data[start:], nil
+	}
+	return start, nil, nil
+}
+
+func isSeparator(c byte) bool {
+	return c == ' ' || c == ',' || c == '\n' || c == '\r' || c == '\t'
+}
+
+//TextOutput writes every value to a text stream on its own line
+func TextOutput(w io.Writer) OutputSink {
+	return &textOutput{w: w}
+}
+
+type textOutput struct {
+	w io.Writer
+}
+
+func (t *textOutput) WriteInt(val int) error {
+	_, err := fmt.Fprintf(t.w, "%d\n", val)
+	return err
+}
+
+//ChanInput reads input values from a channel, blocking until a value is sent. Closing the channel signals that no
+//more input will arrive
+func ChanInput(ch <-chan int) InputSource {
+	return chanInput(ch)
+}
+
+type chanInput <-chan int
+
+func (c chanInput) ReadInt() (int, error) {
+	val, ok := <-c
+	if !ok {
+		return 0, io.EOF
+	}
+	return val, nil
+}
+
+//ChanOutput sends output values on a channel, blocking until they are received. Passing the same channel to
+//ChanOutput for one machine and ChanInput for another wires the first machine's output into the second's input
+func ChanOutput(ch chan<- int) OutputSink {
+	return chanOutput(ch)
+}
+
+type chanOutput chan<- int
+
+func (c chanOutput) WriteInt(val int) error {
+	c <- val
+	return nil
+}
+
+//SliceInput provides a fixed list of input values, in order
+type SliceInput struct {
+	Values []int
+}
+
+//NewSliceInput creates an input source that provides the passed in values
+func NewSliceInput(values ...int) *SliceInput {
+	return &SliceInput{Values: values}
+}
+
+//ReadInt returns the next queued value
+func (s *SliceInput) ReadInt() (int, error) {
+	if len(s.Values) == 0 {
+		return 0, io.EOF
+	}
+	val := s.Values[0]
+	s.Values = s.Values[1:]
+	return val, nil
+}
+
+//Push queues more values to be read
+func (s *SliceInput) Push(values ...int) {
+	s.Values = append(s.Values, values...)
+}
+
+//SliceOutput collects every output value in order
+type SliceOutput struct {
+	Values []int
+}
+
+//WriteInt records the value
+func (s *SliceOutput) WriteInt(val int) error {
+	s.Values = append(s.Values, val)
+	return nil
+}
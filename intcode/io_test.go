@@ -0,0 +1,71 @@
+package intcode
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTextInput(t *testing.T) {
+	in := TextInput(strings.NewReader("5 6\n-7,8\r\n 9"))
+	var got []int
+	for {
+		val, err := in.ReadInt()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("ReadInt() error = %v", err)
+		}
+		got = append(got, val)
+	}
+	if want := []int{5, 6, -7, 8, 9}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadInt() values = %v, want %v", got, want)
+	}
+}
+
+func TestIntcode_Run_SeveralInputsInOneRead(t *testing.T) {
+	var b strings.Builder
+	//adds two inputs together
+	i := Init([]int{3, 11, 3, 12, 1, 11, 12, 11, 4, 11, 99}, strings.NewReader("5 6"), &b)
+	if _, err := i.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := b.String(); got != "11\n" {
+		t.Errorf("Run() output = %s, want 11", got)
+	}
+}
+
+func TestIntcode_Run_Chained(t *testing.T) {
+	//doubles its input
+	program := []int{3, 9, 102, 2, 9, 9, 4, 9, 99, 0}
+	in := make(chan int, 1)
+	between := make(chan int)
+	first := InitIO(program, ChanInput(in), ChanOutput(between))
+	out := &SliceOutput{}
+	second := InitIO(program, ChanInput(between), out)
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := first.Run()
+		errs <- err
+	}()
+	in <- 21
+	if _, err := second.Run(); err != nil {
+		t.Fatalf("second Run() error = %v", err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("first Run() error = %v", err)
+	}
+	if want := []int{84}; !reflect.DeepEqual(out.Values, want) {
+		t.Errorf("output = %v, want %v", out.Values, want)
+	}
+}
+
+func TestIntcode_Run_InputExhausted(t *testing.T) {
+	i := InitIO([]int{3, 0, 3, 0, 99}, NewSliceInput(1), &SliceOutput{})
+	if _, err := i.Run(); err == nil {
+		t.Errorf("Run() error = nil, want an error once the input is exhausted")
+	}
+}
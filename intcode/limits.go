@@ -0,0 +1,152 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package intcode
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+)
+
+var (
+	//ErrStepLimit is reported when a machine has executed as many instructions as its Limits allow
+	ErrStepLimit = errors.New("step limit exceeded")
+	//ErrMemoryLimit is reported when a machine has allocated more memory than its Limits allow
+	ErrMemoryLimit = errors.New("memory limit exceeded")
+)
+
+//contextCheckSteps is how many instructions RunContext executes between checks of its context
+const contextCheckSteps = 1024
+
+//recentBackEdges is how many of the most recently taken back edges a limited machine remembers to tell where it was
+//looping when it is stopped
+const recentBackEdges = 32
+
+//Limits bounds how much a machine may do. A zero value means no limit
+type Limits struct {
+	//MaxSteps is the total number of instructions the machine may execute, counted the same way as Steps
+	MaxSteps int
+	//MaxMemory is the number of memory cells the machine may allocate. Memory is allocated a page at a time, so every
+	//allocated page counts as PageSize cells
+	MaxMemory int
+}
+
+//LimitError is returned when a machine is stopped by its Limits or by the context passed to RunContext. The machine is
+//stopped before executing the instruction at Pos, so it can be resumed with Run after raising the limit
+type LimitError struct {
+	//Err is ErrStepLimit, ErrMemoryLimit or the error of the context that stopped the machine
+	Err error
+	//Pos is the instruction pointer the machine was stopped at
+	Pos int
+	//Steps is the number of instructions executed so far
+	Steps int
+	//Memory is the number of memory cells allocated so far
+	Memory int
+	//Loop is the back edge taken most often out of the last few taken, which is most likely the loop the machine was
+	//stuck in. It is nil when the machine hasn't jumped backwards recently
+	Loop *BackEdge
+}
+
+func (e *LimitError) Error() string {
+	msg := fmt.Sprintf("%s at position %d after %d instructions with %d cells of memory allocated", e.Err, e.Pos, e.Steps, e.Memory)
+	if e.Loop != nil {
+		msg += fmt.Sprintf(", looping from %d back to %d", e.Loop.From, e.Loop.To)
+	}
+	return msg
+}
+
+//Unwrap returns the Err value describing which limit was exceeded
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
+
+//SetLimits bounds how much the machine may do in total. MaxSteps is compared against Steps, which counts every
+//instruction executed since the machine was created, including those inherited through Fork, so a machine that has
+//already executed MaxSteps instructions fails on its next step. Once a limit is exceeded Run and Step return a
+//LimitError
+func (i *Intcode) SetLimits(l Limits) {
+	if l == (Limits{}) {
+		i.limits = nil
+		return
+	}
+	i.limits = &l
+	if i.loops == nil {
+		i.loops = &loopTracker{}
+	}
+}
+
+//RunContext is Run, stopping with a LimitError wrapping the context's error once the context is done. The context is
+//only checked every contextCheckSteps instructions, so a machine may run a little past its deadline
+func (i *Intcode) RunContext(ctx context.Context) (Status, error) {
+	done := ctx.Done()
+	if done != nil && i.loops == nil {
+		i.loops = &loopTracker{}
+	}
+	for n := 0; ; n++ {
+		if done != nil && n%contextCheckSteps == 0 {
+			select {
+			case <-done:
+				return Running, i.limitError(ctx.Err())
+			default:
+			}
+		}
+		status, err := i.Step()
+		if err != nil || status != Running {
+			return status, err
+		}
+	}
+}
+
+//checkLimits returns a LimitError if the machine has used up any of its limits
+func (i *Intcode) checkLimits() error {
+	switch {
+	case i.limits.MaxSteps > 0 && i.steps >= i.limits.MaxSteps:
+		return i.limitError(ErrStepLimit)
+	case i.limits.MaxMemory > 0 && i.memory.PageCount()*PageSize > i.limits.MaxMemory:
+		return i.limitError(ErrMemoryLimit)
+	}
+	return nil
+}
+
+func (i *Intcode) limitError(err error) *LimitError {
+	e := &LimitError{Err: err, Pos: i.pos, Steps: i.steps, Memory: i.memory.PageCount() * PageSize}
+	if i.loops != nil {
+		e.Loop = i.loops.likeliest()
+	}
+	return e
+}
+
+//loopTracker remembers the most recently taken back edges
+type loopTracker struct {
+	edges [recentBackEdges]BackEdge
+	count int
+}
+
+//record remembers the jump from pos to next if it is a back edge
+func (l *loopTracker) record(pos int, opcode int, next int) {
+	if (opcode == 5 || opcode == 6) && next <= pos {
+		l.edges[l.count%recentBackEdges] = BackEdge{From: pos, To: next}
+		l.count++
+	}
+}
+
+//likeliest returns the back edge that was taken the most out of the remembered ones, preferring the most recent one
+//when there is a tie
+func (l *loopTracker) likeliest() *BackEdge {
+	if l.count == 0 {
+		return nil
+	}
+	counts := map[BackEdge]int{}
+	var best BackEdge
+	for n := l.count - 1; n >= 0 && n >= l.count-recentBackEdges; n-- {
+		edge := l.edges[n%recentBackEdges]
+		counts[edge]++
+		if counts[edge] > counts[best] {
+			best = edge
+		}
+	}
+	return &best
+}
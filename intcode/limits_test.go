@@ -0,0 +1,121 @@
+package intcode
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIntcode_SetLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		program   []int
+		limits    Limits
+		want      error
+		wantPos   int
+		wantSteps int
+		wantLoop  *BackEdge
+	}{
+		{
+			name:      "jump to itself",
+			program:   []int{1105, 1, 0},
+			limits:    Limits{MaxSteps: 100},
+			want:      ErrStepLimit,
+			wantPos:   0,
+			wantSteps: 100,
+			wantLoop:  &BackEdge{From: 0, To: 0},
+		},
+		{
+			//counts up in m[7] forever
+			name:      "counter",
+			program:   []int{1001, 7, 1, 7, 1105, 1, 0, 0},
+			limits:    Limits{MaxSteps: 101},
+			want:      ErrStepLimit,
+			wantPos:   4,
+			wantSteps: 101,
+			wantLoop:  &BackEdge{From: 4, To: 0},
+		},
+		{
+			name:      "memory",
+			program:   []int{1101, 1, 0, 1000000, 1105, 1, 0},
+			limits:    Limits{MaxSteps: 100, MaxMemory: PageSize},
+			want:      ErrMemoryLimit,
+			wantPos:   4,
+			wantSteps: 1,
+		},
+		{
+			name:    "within the limits",
+			program: []int{1101, 1, 0, 1000, 99},
+			limits:  Limits{MaxSteps: 2, MaxMemory: 2 * PageSize},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := InitIO(tt.program, nil, nil)
+			i.SetLimits(tt.limits)
+			status, err := i.Run()
+			if tt.want == nil {
+				if err != nil || status != Halted {
+					t.Fatalf("Run() = %v, %v, want Halted", status, err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Run() error = %v, want %v", err, tt.want)
+			}
+			var limitErr *LimitError
+			if !errors.As(err, &limitErr) {
+				t.Fatalf("Run() error = %v, want a LimitError", err)
+			}
+			if limitErr.Pos != tt.wantPos || limitErr.Steps != tt.wantSteps || i.Pos() != tt.wantPos {
+				t.Errorf("LimitError = %+v, want position %d after %d steps", limitErr, tt.wantPos, tt.wantSteps)
+			}
+			if (limitErr.Loop == nil) != (tt.wantLoop == nil) || (tt.wantLoop != nil && *limitErr.Loop != *tt.wantLoop) {
+				t.Errorf("LimitError.Loop = %v, want %v", limitErr.Loop, tt.wantLoop)
+			}
+		})
+	}
+}
+
+func TestIntcode_SetLimits_Resume(t *testing.T) {
+	i := InitIO([]int{1001, 7, 1, 7, 1105, 1, 0, 0}, nil, nil)
+	i.SetLimits(Limits{MaxSteps: 10})
+	if _, err := i.Run(); !errors.Is(err, ErrStepLimit) {
+		t.Fatalf("Run() error = %v, want %v", err, ErrStepLimit)
+	}
+	fork := i.Fork()
+	if _, err := fork.Run(); !errors.Is(err, ErrStepLimit) {
+		t.Errorf("Run() on a fork error = %v, want the fork to keep the limits", err)
+	}
+	i.SetLimits(Limits{MaxSteps: 20})
+	if _, err := i.Run(); !errors.Is(err, ErrStepLimit) || i.Steps() != 20 || i.Peek(7) != 10 {
+		t.Errorf("Run() after raising the limit = %v after %d steps with m[7] = %d, want %v after 20 with 10",
+			err, i.Steps(), i.Peek(7), ErrStepLimit)
+	}
+}
+
+func TestIntcode_RunContext(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	i := InitIO([]int{1105, 1, 0}, nil, nil)
+	if _, err := i.RunContext(cancelled); !errors.Is(err, context.Canceled) || i.Steps() != 0 {
+		t.Errorf("RunContext() = %v after %d steps, want %v before any", err, i.Steps(), context.Canceled)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := i.RunContext(ctx)
+	var limitErr *LimitError
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.As(err, &limitErr) {
+		t.Fatalf("RunContext() error = %v, want a LimitError wrapping %v", err, context.DeadlineExceeded)
+	}
+	if limitErr.Steps == 0 || limitErr.Loop == nil || *limitErr.Loop != (BackEdge{From: 0, To: 0}) {
+		t.Errorf("LimitError = %+v, want it to have run the loop at 0", limitErr)
+	}
+
+	i = InitIO([]int{1101, 2, 3, 0, 99}, nil, nil)
+	if status, err := i.RunContext(context.Background()); status != Halted || err != nil {
+		t.Errorf("RunContext() = %v, %v, want Halted", status, err)
+	}
+}
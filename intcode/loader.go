@@ -0,0 +1,50 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package intcode
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+//LoadIntCodeProgram reads a comma separated Intcode program from the passed in file
+func LoadIntCodeProgram(filename string) ([]int, error) {
+	csvfile, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer csvfile.Close()
+	return ReadIntCodeProgram(csvfile)
+}
+
+//ReadIntCodeProgram parses a comma separated Intcode program from the passed in reader
+func ReadIntCodeProgram(in io.Reader) ([]int, error) {
+	// Parse the file
+	r := csv.NewReader(in)
+
+	// Iterate through the records
+	// Read each record from csv
+	tape, err := r.Read()
+	if err == io.EOF {
+		return nil, errors.New("input file did not contain an Intcode program")
+	}
+	if err != nil {
+		return nil, err
+	}
+	intcodes := make([]int, 0)
+	for _, opcode := range tape {
+		curInt, err := strconv.Atoi(opcode)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("unable to convert opcode '%s' to a number", opcode))
+		}
+		intcodes = append(intcodes, curInt)
+	}
+	return intcodes, nil
+}
@@ -0,0 +1,48 @@
+package intcode
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadIntCodeProgram(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []int
+		wantErr bool
+	}{
+		{
+			name:  "single_line",
+			input: "1,9,10,3,2,3,11,0,99,30,40,50",
+			want:  []int{1, 9, 10, 3, 2, 3, 11, 0, 99, 30, 40, 50},
+		},
+		{
+			name:  "trailing_newline_and_negatives",
+			input: "1101,100,-1,4,0\n",
+			want:  []int{1101, 100, -1, 4, 0},
+		},
+		{
+			name:    "empty",
+			input:   "",
+			wantErr: true,
+		},
+		{
+			name:    "not_a_number",
+			input:   "1,a,3",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadIntCodeProgram(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadIntCodeProgram() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadIntCodeProgram() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
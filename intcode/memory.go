@@ -0,0 +1,138 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package intcode
+
+import "fmt"
+
+//PageSize is the number of memory cells held in a single page of Intcode memory
+const PageSize = 1024
+
+//Memory is the addressable memory of an Intcode machine. Every non-negative address can be read or written; cells that
+//have never been written read as 0. Memory is split into fixed size pages that are only allocated when a cell inside of
+//them is written to, so programs that poke very high addresses only pay for the pages they actually touch.
+//
+//Pages can be shared between copies of memory made by Fork. A shared page is never modified, the first write to one
+//copies it and the copy is owned by the memory that wrote to it
+type Memory struct {
+	pages map[int][]int
+	//owned holds the pages that are not shared with any other memory and can be written to in place
+	owned map[int]bool
+	size  int
+	//decoded caches the instructions decoded by the interpreter in chunks of decodeChunk addresses. Writes drop any
+	//cached instruction they land on
+	decoded [][]*decodedInstruction
+	//decodedOwned holds whether each chunk of decoded is not shared with any other memory
+	decodedOwned []bool
+}
+
+//NewMemory creates memory holding a copy of the passed in program starting at address 0
+func NewMemory(program []int) *Memory {
+	m := &Memory{
+		pages: make(map[int][]int, len(program)/PageSize+1),
+		owned: make(map[int]bool, len(program)/PageSize+1),
+	}
+	for addr, val := range program {
+		m.Write(addr, val)
+	}
+	m.size = len(program)
+	return m
+}
+
+//Read returns the value stored at addr. Addresses that have never been written to read as 0
+func (m *Memory) Read(addr int) int {
+	checkAddress(addr)
+	page, ok := m.pages[addr/PageSize]
+	if !ok {
+		return 0
+	}
+	return page[addr%PageSize]
+}
+
+//Write stores val at addr, allocating the page that holds addr if it doesn't exist yet and copying it first if it is
+//shared with a fork
+func (m *Memory) Write(addr int, val int) {
+	checkAddress(addr)
+	if len(m.decoded) > 0 {
+		m.invalidate(addr)
+	}
+	num := addr / PageSize
+	page, ok := m.pages[num]
+	switch {
+	case !ok && val == 0:
+		//unallocated cells already read as 0, no need to allocate a page to store one
+		m.grow(addr)
+		return
+	case !ok:
+		page = make([]int, PageSize)
+		m.pages[num] = page
+		m.owned[num] = true
+	case !m.owned[num]:
+		page = append([]int(nil), page...)
+		m.pages[num] = page
+		m.owned[num] = true
+	}
+	page[addr%PageSize] = val
+	m.grow(addr)
+}
+
+//Fork returns a copy of the memory that shares every page with the original. Neither copy sees the writes made to the
+//other afterwards; a page is only copied the first time either side writes to it, so forking costs a map entry per
+//allocated page rather than a copy of every cell
+func (m *Memory) Fork() *Memory {
+	//every page becomes shared, including for the original. Memory that has already been forked and not written to
+	//since is left untouched so that one base can be forked from several goroutines once it has been forked once
+	if len(m.owned) > 0 {
+		m.owned = map[int]bool{}
+	}
+	pages := make(map[int][]int, len(m.pages))
+	for num, page := range m.pages {
+		pages[num] = page
+	}
+	fork := &Memory{pages: pages, owned: map[int]bool{}, size: m.size}
+	//decoded instructions only depend on the cells they were decoded from, so the cache is shared the same way
+	m.forkDecoded(fork)
+	return fork
+}
+
+//OwnedPages returns the number of allocated pages that are not shared with a fork
+func (m *Memory) OwnedPages() int {
+	return len(m.owned)
+}
+
+//Size returns one past the highest address that has been loaded or written to
+func (m *Memory) Size() int {
+	return m.size
+}
+
+//PageCount returns the number of pages that have been allocated
+func (m *Memory) PageCount() int {
+	return len(m.pages)
+}
+
+func (m *Memory) grow(addr int) {
+	if addr >= m.size {
+		m.size = addr + 1
+	}
+}
+
+func checkAddress(addr int) {
+	if addr < 0 {
+		panic(fmt.Sprintf("intcode: negative memory address %d", addr))
+	}
+}
+
+//copyPages returns a deep copy of every allocated page
+func (m *Memory) copyPages() map[int][]int {
+	return copyPages(m.pages)
+}
+
+func copyPages(pages map[int][]int) map[int][]int {
+	copied := make(map[int][]int, len(pages))
+	for num, page := range pages {
+		copied[num] = append([]int(nil), page...)
+	}
+	return copied
+}
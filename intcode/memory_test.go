@@ -0,0 +1,112 @@
+package intcode
+
+import "testing"
+
+func TestMemory_ReadWrite(t *testing.T) {
+	m := NewMemory([]int{1, 2, 3})
+	if got := m.Read(2); got != 3 {
+		t.Errorf("Read(2) = %d, want 3", got)
+	}
+	if got := m.Read(3); got != 0 {
+		t.Errorf("Read(3) past program = %d, want 0", got)
+	}
+	if got := m.Read(1 << 40); got != 0 {
+		t.Errorf("Read(1<<40) = %d, want 0", got)
+	}
+	if got := m.PageCount(); got != 1 {
+		t.Errorf("PageCount() after reads = %d, want 1", got)
+	}
+
+	m.Write(1<<40, 42)
+	if got := m.Read(1 << 40); got != 42 {
+		t.Errorf("Read(1<<40) = %d, want 42", got)
+	}
+	if got := m.PageCount(); got != 2 {
+		t.Errorf("PageCount() after high write = %d, want 2", got)
+	}
+	if got := m.Size(); got != 1<<40+1 {
+		t.Errorf("Size() = %d, want %d", got, 1<<40+1)
+	}
+
+	m.Write(PageSize*5, 0)
+	if got := m.PageCount(); got != 2 {
+		t.Errorf("PageCount() after writing zero = %d, want 2", got)
+	}
+}
+
+func TestNewMemory_CopiesProgram(t *testing.T) {
+	program := []int{1, 0, 0, 0, 99}
+	i := Init(program, nil, nil)
+	if _, err := i.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if program[0] != 1 {
+		t.Errorf("program[0] = %d, Run() modified the loaded slice", program[0])
+	}
+	if got := i.Peek(0); got != 2 {
+		t.Errorf("Peek(0) = %d, want 2", got)
+	}
+}
+
+func TestMemory_Fork(t *testing.T) {
+	program := make([]int, PageSize*3)
+	for addr := range program {
+		program[addr] = addr
+	}
+	parent := NewMemory(program)
+	child := parent.Fork()
+	if got := parent.OwnedPages(); got != 0 {
+		t.Errorf("parent OwnedPages() after Fork = %d, want 0", got)
+	}
+
+	child.Write(1, -1)
+	parent.Write(PageSize*2, -2)
+	if got := parent.Read(1); got != 1 {
+		t.Errorf("parent Read(1) = %d, want 1", got)
+	}
+	if got := child.Read(PageSize * 2); got != PageSize*2 {
+		t.Errorf("child Read(%d) = %d, want %d", PageSize*2, got, PageSize*2)
+	}
+	if got := child.Read(1); got != -1 {
+		t.Errorf("child Read(1) = %d, want -1", got)
+	}
+	if got := child.OwnedPages(); got != 1 {
+		t.Errorf("child OwnedPages() = %d, want 1", got)
+	}
+	if got := parent.OwnedPages(); got != 1 {
+		t.Errorf("parent OwnedPages() = %d, want 1", got)
+	}
+
+	//pages allocated after the fork belong to whoever allocated them
+	child.Write(PageSize*10, 7)
+	if got := parent.Read(PageSize * 10); got != 0 {
+		t.Errorf("parent Read(%d) = %d, want 0", PageSize*10, got)
+	}
+	if got := parent.PageCount(); got != 3 {
+		t.Errorf("parent PageCount() = %d, want 3", got)
+	}
+}
+
+func TestIntcode_Fork(t *testing.T) {
+	//read one input, add it to itself and output it
+	base := InitIO([]int{3, 9, 1, 9, 9, 9, 4, 9, 99, 0}, nil, nil)
+	if status, _ := base.Run(); status != NeedsInput {
+		t.Fatalf("Run() status = %v, want %v", status, NeedsInput)
+	}
+	for _, in := range []int{3, 20} {
+		fork := base.Fork()
+		fork.Input(in)
+		if status, err := fork.Run(); status != HasOutput || err != nil {
+			t.Fatalf("fork Run() = %v, %v, want HasOutput", status, err)
+		}
+		if got := fork.Output(); got != in*2 {
+			t.Errorf("fork Output() = %d, want %d", got, in*2)
+		}
+	}
+	if got := base.Peek(9); got != 0 {
+		t.Errorf("base Peek(9) = %d, want 0", got)
+	}
+	if got := base.Pos(); got != 0 {
+		t.Errorf("base Pos() = %d, want 0", got)
+	}
+}
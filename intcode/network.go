@@ -0,0 +1,294 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package intcode
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"runtime"
+	"sync"
+)
+
+//NATAddress is the address of the NAT. Packets sent to it are kept by the NAT instead of being delivered to a machine,
+//and the last one is sent on to address 0 whenever the network goes idle
+const NATAddress = 255
+
+//idleReads is the number of input reads in a row that must find a machine's queue empty before it counts as idle
+const idleReads = 2
+
+//turnSteps is the most instructions a machine runs in one turn with RoundRobin scheduling, so a machine that loops
+//without reading input can't keep the others from running
+const turnSteps = 1000
+
+//Scheduling is how the machines of a network share the processor
+type Scheduling int
+
+const (
+	//RoundRobin runs the machines one at a time in address order, each until it has read a single input value or run
+	//turnSteps instructions. Runs are deterministic, so the same program always produces the same traffic in the same
+	//order
+	RoundRobin Scheduling = iota
+	//Concurrent runs every machine in its own goroutine. The order packets are sent in depends on the Go scheduler
+	Concurrent
+)
+
+func (s Scheduling) String() string {
+	if s == Concurrent {
+		return "concurrent"
+	}
+	return "round-robin"
+}
+
+//ErrNetworkIdle is returned by Run when the network goes idle before the NAT has a packet to send
+var ErrNetworkIdle = errors.New("the network went idle before the NAT received a packet")
+
+//errNetworkStopped is returned to the machines still running in a network once it has stopped
+var errNetworkStopped = errors.New("network stopped")
+
+//Packet is an (X, Y) pair sent by the machine at From to the one at To
+type Packet struct {
+	From int
+	To   int
+	X    int
+	Y    int
+}
+
+//Network boots copies of an Intcode program as machines on a network. Every machine reads its address as its first
+//input, then sends packets by outputting the destination address, X and Y, and receives them as X followed by Y. A
+//machine reading input when no packet is waiting for it reads -1
+type Network struct {
+	Program    []int
+	Size       int
+	Scheduling Scheduling
+	//OnPacket is called with every packet sent, including those sent by the NAT, before it is delivered
+	OnPacket func(p Packet)
+	//OnIdle is called each time the network goes idle, with the packet the NAT is about to send to address 0
+	OnIdle func(p Packet)
+}
+
+//NewNetwork creates a network of size machines running program, with addresses 0 to size-1
+func NewNetwork(program []int, size int, scheduling Scheduling) *Network {
+	return &Network{Program: program, Size: size, Scheduling: scheduling}
+}
+
+//Run boots the network and routes packets until stop returns true for one of them, returning that packet. stop is
+//called after OnPacket for every packet sent, including those sent by the NAT. The hooks and stop are never called
+//concurrently, even with Concurrent scheduling.
+//
+//The network is idle once every running machine has found its queue empty idleReads times in a row without sending
+//anything since, and Run returns ErrNetworkIdle if that happens before the NAT has received a packet. With Concurrent
+//scheduling a machine that is still working on something after reading -1 can be mistaken for an idle one
+func (n *Network) Run(stop func(p Packet) bool) (Packet, error) {
+	if n.Size < 1 || n.Size > NATAddress {
+		return Packet{}, errors.New(fmt.Sprintf("a network needs between 1 and %d machines, not %d", NATAddress, n.Size))
+	}
+	r := &networkRun{network: n, stop: stop, nodes: make([]*networkNode, n.Size)}
+	machines := make([]*Intcode, n.Size)
+	for addr := range r.nodes {
+		node := &networkNode{run: r, addr: addr, queue: []int{addr}}
+		r.nodes[addr] = node
+		machines[addr] = InitIO(n.Program, node, node)
+	}
+	if n.Scheduling == Concurrent {
+		r.concurrent(machines)
+	} else {
+		r.roundRobin(machines)
+	}
+	if r.err != nil {
+		return Packet{}, r.err
+	}
+	return r.result, nil
+}
+
+//networkRun is the state of a single run of a network. Everything in it is guarded by mu
+type networkRun struct {
+	network *Network
+	stop    func(p Packet) bool
+	mu      sync.Mutex
+	nodes   []*networkNode
+	nat     *Packet
+	stopped bool
+	result  Packet
+	err     error
+	//cancel stops the machines of a Concurrent run that are looping without reading input or sending packets
+	cancel context.CancelFunc
+}
+
+//roundRobin gives each machine in turn the time to read one input value, until the network stops
+func (r *networkRun) roundRobin(machines []*Intcode) {
+	for {
+		running := false
+		for addr, machine := range machines {
+			node := r.nodes[addr]
+			if node.halted {
+				continue
+			}
+			running = true
+			for reads, steps := node.reads, 0; node.reads == reads && steps < turnSteps; steps++ {
+				status, err := machine.Step()
+				if r.stopped {
+					return
+				}
+				if err != nil {
+					r.fail(addr, err)
+					return
+				}
+				if status == Halted {
+					node.halted = true
+					break
+				}
+			}
+		}
+		if !running {
+			r.fail(-1, errors.New("every machine halted before the network stopped"))
+			return
+		}
+	}
+}
+
+//concurrent runs every machine in its own goroutine until the network stops
+func (r *networkRun) concurrent(machines []*Intcode) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.cancel = cancel
+	var wg sync.WaitGroup
+	for addr, machine := range machines {
+		wg.Add(1)
+		go func(addr int, machine *Intcode) {
+			defer wg.Done()
+			_, err := machine.RunContext(ctx)
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.nodes[addr].halted = true
+			if err != nil && !r.stopped {
+				r.fail(addr, err)
+			}
+		}(addr, machine)
+	}
+	wg.Wait()
+	if !r.stopped {
+		r.fail(-1, errors.New("every machine halted before the network stopped"))
+	}
+}
+
+//fail stops the network with the error of the machine at addr, or of the network itself for an addr of -1
+func (r *networkRun) fail(addr int, err error) {
+	if r.stopped {
+		return
+	}
+	r.halt()
+	if addr >= 0 {
+		err = errors.Wrap(err, fmt.Sprintf("machine %d failed", addr))
+	}
+	r.err = err
+}
+
+//halt stops the network, cancelling the machines of a Concurrent run
+func (r *networkRun) halt() {
+	r.stopped = true
+	if r.cancel != nil {
+		r.cancel()
+	}
+}
+
+//send routes a packet, returning errNetworkStopped once the network has stopped
+func (r *networkRun) send(p Packet) error {
+	if r.network.OnPacket != nil {
+		r.network.OnPacket(p)
+	}
+	switch {
+	case p.To == NATAddress:
+		r.nat = &p
+	case p.To >= 0 && p.To < len(r.nodes):
+		r.nodes[p.To].queue = append(r.nodes[p.To].queue, p.X, p.Y)
+	default:
+		r.fail(-1, errors.New(fmt.Sprintf("machine %d sent a packet to unknown address %d", p.From, p.To)))
+		return errNetworkStopped
+	}
+	if r.stop != nil && r.stop(p) {
+		r.halt()
+		r.result = p
+		return errNetworkStopped
+	}
+	return nil
+}
+
+//checkIdle has the NAT send its last packet to address 0 if the network is idle
+func (r *networkRun) checkIdle() error {
+	for _, node := range r.nodes {
+		if !node.halted && (node.emptyReads < idleReads || len(node.queue) > 0) {
+			return nil
+		}
+	}
+	if r.nat == nil {
+		r.fail(-1, ErrNetworkIdle)
+		return errNetworkStopped
+	}
+	p := Packet{From: NATAddress, To: 0, X: r.nat.X, Y: r.nat.Y}
+	if r.network.OnIdle != nil {
+		r.network.OnIdle(p)
+	}
+	for _, node := range r.nodes {
+		node.emptyReads = 0
+	}
+	return r.send(p)
+}
+
+//networkNode is the network interface of a single machine, both its input source and its output sink
+type networkNode struct {
+	run        *networkRun
+	addr       int
+	queue      []int
+	reads      int
+	emptyReads int
+	halted     bool
+	//sending holds the destination and X of a packet being output
+	sending []int
+}
+
+func (n *networkNode) ReadInt() (int, error) {
+	n.run.mu.Lock()
+	if n.run.stopped {
+		n.run.mu.Unlock()
+		return 0, errNetworkStopped
+	}
+	n.reads++
+	if len(n.queue) > 0 {
+		val := n.queue[0]
+		n.queue = n.queue[1:]
+		n.emptyReads = 0
+		n.run.mu.Unlock()
+		return val, nil
+	}
+	n.emptyReads++
+	err := n.run.checkIdle()
+	n.run.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	if n.run.network.Scheduling == Concurrent {
+		//nothing to do until another machine sends something, let it run
+		runtime.Gosched()
+	}
+	return -1, nil
+}
+
+func (n *networkNode) WriteInt(val int) error {
+	n.run.mu.Lock()
+	defer n.run.mu.Unlock()
+	if n.run.stopped {
+		return errNetworkStopped
+	}
+	if len(n.sending) < 2 {
+		n.sending = append(n.sending, val)
+		return nil
+	}
+	p := Packet{From: n.addr, To: n.sending[0], X: n.sending[1], Y: val}
+	n.sending = n.sending[:0]
+	n.emptyReads = 0
+	return n.run.send(p)
+}
@@ -0,0 +1,139 @@
+package intcode
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+//ringSrc passes a packet around a ring of 4 machines, each adding its address to Y. Machine 0 starts the ring and the
+//last machine sends the packet to the NAT
+const ringSrc = `
+	IN [@addr]
+	JT [@addr], @loop
+	OUT 1              ; machine 0 starts the ring with a packet to machine 1
+	OUT 0
+	OUT 1
+loop:
+	IN [@x]
+	EQ [@x], -1, [@tmp]
+	JT [@tmp], @loop
+	IN [@y]
+	ADD [@y], [@addr], [@y]
+	ADD [@addr], 1, [@dst]
+	EQ [@dst], [@size], [@tmp]
+	JF [@tmp], @send
+	ADD 255, 0, [@dst]
+send:
+	OUT [@dst]
+	OUT [@x]
+	OUT [@y]
+	JT 1, @loop
+addr: .data 0
+x:    .data 0
+y:    .data 0
+dst:  .data 0
+tmp:  .data 0
+size: .data 4`
+
+func TestNetwork_Run(t *testing.T) {
+	ring, err := Assemble(strings.NewReader(ringSrc))
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	tests := []struct {
+		name     string
+		stop     func() func(p Packet) bool
+		want     Packet
+		wantSent int
+		wantIdle int
+	}{
+		{
+			name: "first packet to the NAT",
+			stop: func() func(p Packet) bool {
+				return func(p Packet) bool { return p.To == NATAddress }
+			},
+			want:     Packet{From: 3, To: NATAddress, X: 0, Y: 7},
+			wantSent: 4,
+		},
+		{
+			name: "second packet from the NAT",
+			stop: func() func(p Packet) bool {
+				sent := 0
+				return func(p Packet) bool {
+					if p.From == NATAddress {
+						sent++
+					}
+					return sent == 2
+				}
+			},
+			want:     Packet{From: NATAddress, To: 0, X: 0, Y: 13},
+			wantSent: 10,
+			wantIdle: 2,
+		},
+	}
+	for _, tt := range tests {
+		for _, scheduling := range []Scheduling{RoundRobin, Concurrent} {
+			t.Run(tt.name+"/"+scheduling.String(), func(t *testing.T) {
+				n := NewNetwork(ring, 4, scheduling)
+				sent, idle := 0, 0
+				n.OnPacket = func(p Packet) { sent++ }
+				n.OnIdle = func(p Packet) { idle++ }
+				got, err := n.Run(tt.stop())
+				if err != nil {
+					t.Fatalf("Run() error = %v", err)
+				}
+				if got != tt.want {
+					t.Errorf("Run() = %+v, want %+v", got, tt.want)
+				}
+				if sent != tt.wantSent || idle != tt.wantIdle {
+					t.Errorf("observed %d packets and %d idle periods, want %d and %d", sent, idle, tt.wantSent, tt.wantIdle)
+				}
+			})
+		}
+	}
+}
+
+func TestNetwork_Run_LoopingMachine(t *testing.T) {
+	//machine 0 loops forever without reading input, machine 1 sends a packet to the NAT and halts
+	program := []int{3, 100, 1005, 100, 8, 1105, 1, 5, 104, 255, 104, 1, 104, 2, 99}
+	for _, scheduling := range []Scheduling{RoundRobin, Concurrent} {
+		t.Run(scheduling.String(), func(t *testing.T) {
+			got, err := NewNetwork(program, 2, scheduling).Run(func(p Packet) bool { return p.To == NATAddress })
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if want := (Packet{From: 1, To: NATAddress, X: 1, Y: 2}); got != want {
+				t.Errorf("Run() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestNetwork_Run_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []int
+		size    int
+		want    error
+	}{
+		{name: "no machines", program: []int{99}, size: 0},
+		{name: "every machine halts", program: []int{3, 0, 99}, size: 2},
+		{name: "unknown address", program: []int{3, 0, 104, 7, 104, 0, 104, 0, 99}, size: 2},
+		{name: "idle without a NAT packet", program: []int{3, 100, 3, 101, 1105, 1, 2}, size: 2, want: ErrNetworkIdle},
+		{name: "machine fails", program: []int{3, 0, 42}, size: 2, want: ErrUnknownOpcode},
+	}
+	for _, tt := range tests {
+		for _, scheduling := range []Scheduling{RoundRobin, Concurrent} {
+			t.Run(tt.name+"/"+scheduling.String(), func(t *testing.T) {
+				_, err := NewNetwork(tt.program, tt.size, scheduling).Run(nil)
+				if err == nil {
+					t.Fatalf("Run() error = nil, want an error")
+				}
+				if tt.want != nil && !errors.Is(err, tt.want) {
+					t.Errorf("Run() error = %v, want %v", err, tt.want)
+				}
+			})
+		}
+	}
+}
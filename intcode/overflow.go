@@ -0,0 +1,50 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package intcode
+
+import "fmt"
+
+const (
+	maxInt = int(^uint(0) >> 1)
+	minInt = -maxInt - 1
+)
+
+//OverflowError is returned by the int backend when an add or multiply instruction produces a value that does not fit in
+//an int. Programs that need values that large should be run on the BigBackend
+type OverflowError struct {
+	//Pos is the position of the instruction that overflowed
+	Pos int
+	//Instruction is the raw instruction value, including its parameter modes
+	Instruction int
+	//A and B are the operands of the instruction
+	A int
+	B int
+}
+
+func (e *OverflowError) Error() string {
+	op := "+"
+	if e.Instruction%100 == 2 {
+		op = "*"
+	}
+	return fmt.Sprintf("integer overflow at position %d (instruction %d): %d %s %d does not fit in an int", e.Pos, e.Instruction, e.A, op, e.B)
+}
+
+func addOverflows(a int, b int) bool {
+	if b > 0 {
+		return a > maxInt-b
+	}
+	return a < minInt-b
+}
+
+func mulOverflows(a int, b int) bool {
+	if a == 0 || b == 0 {
+		return false
+	}
+	if (a == -1 && b == minInt) || (b == -1 && a == minInt) {
+		return true
+	}
+	return (a*b)/b != a
+}
@@ -0,0 +1,162 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package intcode
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+//WritePprof writes the profile to w in the gzipped protocol buffer format read by go tool pprof. Every executed address
+//becomes a function named after its disassembly, called from a function named after its opcode, so pprof's reports
+//and graphs map straight back to the program. Addresses are disassembled from the machine's memory as it is when the
+//profile is written
+func (p *Profile) WritePprof(w io.Writer, m *Intcode) error {
+	strs := &stringTable{index: map[string]int{"": 0}, strs: []string{""}}
+	var profile protoBuf
+	valueType := func(kind string, unit string) []byte {
+		var vt protoBuf
+		vt.int64Field(1, int64(strs.add(kind)))
+		vt.int64Field(2, int64(strs.add(unit)))
+		return vt.data
+	}
+	profile.bytesField(1, valueType("instructions", "count"))
+
+	addrs := make([]int, 0, len(p.ByAddress))
+	for addr := range p.ByAddress {
+		addrs = append(addrs, addr)
+	}
+	sort.Ints(addrs)
+
+	//every address and every opcode gets its own function and location, all sharing the same id
+	nextID := uint64(1)
+	opcodeIDs := map[int]uint64{}
+	var functions, locations []protoBuf
+	addFunction := func(name string, line int) uint64 {
+		id := nextID
+		nextID++
+		var fn protoBuf
+		fn.uint64Field(1, id)
+		fn.int64Field(2, int64(strs.add(name)))
+		fn.int64Field(3, int64(strs.add(name)))
+		fn.int64Field(4, int64(strs.add("intcode")))
+		fn.int64Field(5, int64(line))
+		functions = append(functions, fn)
+
+		var ln protoBuf
+		ln.uint64Field(1, id)
+		ln.int64Field(2, int64(line))
+		var loc protoBuf
+		loc.uint64Field(1, id)
+		loc.uint64Field(3, uint64(line))
+		loc.bytesField(4, ln.data)
+		locations = append(locations, loc)
+		return id
+	}
+
+	for _, addr := range addrs {
+		in, _ := m.Decode(addr)
+		opcode := in.Opcode
+		if in.IsData() {
+			opcode, _, _, _ = decode(m.Peek(addr))
+		}
+		opcodeID, ok := opcodeIDs[opcode]
+		if !ok {
+			opcodeID = addFunction(mnemonicOf(opcode), 0)
+			opcodeIDs[opcode] = opcodeID
+		}
+		operands := strings.Join(in.Operands(), ", ")
+		addrID := addFunction(strings.TrimSpace(fmt.Sprintf("%d: %s %s", addr, in.Mnemonic, operands)), addr)
+
+		var sample protoBuf
+		sample.packedUint64(1, []uint64{addrID, opcodeID})
+		sample.packedInt64(2, []int64{int64(p.ByAddress[addr])})
+		profile.bytesField(2, sample.data)
+	}
+	for _, loc := range locations {
+		profile.bytesField(4, loc.data)
+	}
+	for _, fn := range functions {
+		profile.bytesField(5, fn.data)
+	}
+	for _, str := range strs.strs {
+		profile.bytesField(6, []byte(str))
+	}
+	profile.bytesField(11, valueType("instructions", "count"))
+	profile.int64Field(12, 1)
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(profile.data); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+type stringTable struct {
+	index map[string]int
+	strs  []string
+}
+
+func (s *stringTable) add(str string) int {
+	if idx, ok := s.index[str]; ok {
+		return idx
+	}
+	s.index[str] = len(s.strs)
+	s.strs = append(s.strs, str)
+	return len(s.strs) - 1
+}
+
+//protoBuf encodes the handful of protocol buffer field types needed by the pprof format
+type protoBuf struct {
+	data []byte
+}
+
+func (b *protoBuf) varint(x uint64) {
+	for x >= 0x80 {
+		b.data = append(b.data, byte(x)|0x80)
+		x >>= 7
+	}
+	b.data = append(b.data, byte(x))
+}
+
+func (b *protoBuf) tag(field int, wireType int) {
+	b.varint(uint64(field)<<3 | uint64(wireType))
+}
+
+func (b *protoBuf) uint64Field(field int, x uint64) {
+	b.tag(field, 0)
+	b.varint(x)
+}
+
+func (b *protoBuf) int64Field(field int, x int64) {
+	b.tag(field, 0)
+	b.varint(uint64(x))
+}
+
+func (b *protoBuf) bytesField(field int, data []byte) {
+	b.tag(field, 2)
+	b.varint(uint64(len(data)))
+	b.data = append(b.data, data...)
+}
+
+func (b *protoBuf) packedUint64(field int, xs []uint64) {
+	var packed protoBuf
+	for _, x := range xs {
+		packed.varint(x)
+	}
+	b.bytesField(field, packed.data)
+}
+
+func (b *protoBuf) packedInt64(field int, xs []int64) {
+	var packed protoBuf
+	for _, x := range xs {
+		packed.varint(uint64(x))
+	}
+	b.bytesField(field, packed.data)
+}
@@ -0,0 +1,171 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package intcode
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+//Profile counts the instructions a machine executes, by address and by opcode, along with how often each backwards
+//jump is taken. Attach one to a machine with SetProfile before running it
+type Profile struct {
+	//Steps is the total number of instructions executed
+	Steps int
+	//ByAddress counts the instructions executed at each address
+	ByAddress map[int]int
+	//ByOpcode counts the instructions executed with each opcode
+	ByOpcode map[int]int
+	//BackEdges counts each taken jump (opcodes 5 and 6) that goes back to or before its own address. Every back edge
+	//closes a loop, so the counts show how many times each loop went around
+	BackEdges map[BackEdge]int
+}
+
+//BackEdge is a jump from the instruction at From back to the instruction at To
+type BackEdge struct {
+	From int
+	To   int
+}
+
+//NewProfile creates an empty profile
+func NewProfile() *Profile {
+	return &Profile{
+		ByAddress: map[int]int{},
+		ByOpcode:  map[int]int{},
+		BackEdges: map[BackEdge]int{},
+	}
+}
+
+//SetProfile attaches a profile that will count every instruction the machine executes, or detaches the current one
+//when passed nil
+func (i *Intcode) SetProfile(p *Profile) {
+	i.profile = p
+}
+
+//record counts a single executed instruction that moved the instruction pointer from pos to next
+func (p *Profile) record(pos int, opcode int, next int) {
+	p.Steps++
+	p.ByAddress[pos]++
+	p.ByOpcode[opcode]++
+	if (opcode == 5 || opcode == 6) && next <= pos {
+		p.BackEdges[BackEdge{From: pos, To: next}]++
+	}
+}
+
+//Hotspot is the execution count of a single address
+type Hotspot struct {
+	Addr  int
+	Count int
+}
+
+//Hotspots returns every executed address ordered from the most executed to the least
+func (p *Profile) Hotspots() []Hotspot {
+	spots := make([]Hotspot, 0, len(p.ByAddress))
+	for addr, count := range p.ByAddress {
+		spots = append(spots, Hotspot{Addr: addr, Count: count})
+	}
+	sort.Slice(spots, func(a, b int) bool {
+		if spots[a].Count != spots[b].Count {
+			return spots[a].Count > spots[b].Count
+		}
+		return spots[a].Addr < spots[b].Addr
+	})
+	return spots
+}
+
+//Loop is a loop found through one of its back edges
+type Loop struct {
+	BackEdge
+	//Iterations is how many times the back edge was taken
+	Iterations int
+	//Instructions is the number of instructions executed between the start and end of the loop over the whole run
+	Instructions int
+}
+
+//Loops returns every loop closed by a back edge, ordered from the most instructions executed inside of it to the least
+func (p *Profile) Loops() []Loop {
+	loops := make([]Loop, 0, len(p.BackEdges))
+	for edge, count := range p.BackEdges {
+		loop := Loop{BackEdge: edge, Iterations: count}
+		for addr, executed := range p.ByAddress {
+			if addr >= edge.To && addr <= edge.From {
+				loop.Instructions += executed
+			}
+		}
+		loops = append(loops, loop)
+	}
+	sort.Slice(loops, func(a, b int) bool {
+		if loops[a].Instructions != loops[b].Instructions {
+			return loops[a].Instructions > loops[b].Instructions
+		}
+		return loops[a].From < loops[b].From
+	})
+	return loops
+}
+
+//WriteReport writes a summary of the profile to w, listing at most top entries in each section. Addresses are
+//disassembled from the machine's memory as it is when the report is written, so code the program modified is shown
+//as it ended up
+func (p *Profile) WriteReport(w io.Writer, m *Intcode, top int) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d instructions executed\n", p.Steps)
+
+	fmt.Fprintf(&b, "\nby opcode:\n")
+	opcodes := make([]int, 0, len(p.ByOpcode))
+	for opcode := range p.ByOpcode {
+		opcodes = append(opcodes, opcode)
+	}
+	sort.Slice(opcodes, func(a, b int) bool {
+		return p.ByOpcode[opcodes[a]] > p.ByOpcode[opcodes[b]] ||
+			(p.ByOpcode[opcodes[a]] == p.ByOpcode[opcodes[b]] && opcodes[a] < opcodes[b])
+	})
+	for _, opcode := range opcodes {
+		fmt.Fprintf(&b, "  %-4s %10d %6.2f%%\n", mnemonicOf(opcode), p.ByOpcode[opcode], p.percent(p.ByOpcode[opcode]))
+	}
+
+	fmt.Fprintf(&b, "\nhottest addresses:\n")
+	for idx, spot := range p.Hotspots() {
+		if idx >= top {
+			break
+		}
+		in, _ := m.Decode(spot.Addr)
+		fmt.Fprintf(&b, "  %10d %6.2f%%  %s\n", spot.Count, p.percent(spot.Count), strings.TrimSpace(in.String()))
+	}
+
+	loops := p.Loops()
+	if len(loops) > 0 {
+		fmt.Fprintf(&b, "\nhottest loops:\n")
+	}
+	for idx, loop := range loops {
+		if idx >= top {
+			break
+		}
+		fmt.Fprintf(&b, "  %d..%d: %d iterations, %d instructions (%.2f%%)\n", loop.To, loop.From, loop.Iterations,
+			loop.Instructions, p.percent(loop.Instructions))
+		for _, addr := range []int{loop.To, loop.From} {
+			in, _ := m.Decode(addr)
+			fmt.Fprintf(&b, "      %s\n", strings.TrimSpace(in.String()))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (p *Profile) percent(count int) float64 {
+	if p.Steps == 0 {
+		return 0
+	}
+	return 100 * float64(count) / float64(p.Steps)
+}
+
+func mnemonicOf(opcode int) string {
+	if mnemonic, ok := Mnemonics[opcode]; ok {
+		return mnemonic
+	}
+	return fmt.Sprintf("op%d", opcode)
+}
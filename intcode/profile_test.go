@@ -0,0 +1,91 @@
+package intcode
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestIntcode_SetProfile(t *testing.T) {
+	//counts [@n] down to 0, outputting each value
+	program, err := Assemble(strings.NewReader(`
+		loop: OUT [@n]
+			ADD [@n], -1, [@n]
+			JT [@n], @loop
+			HLT
+		n: .data 5`))
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	i := InitIO(program, nil, &SliceOutput{})
+	profile := NewProfile()
+	i.SetProfile(profile)
+	if _, err := i.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if profile.Steps != 16 || profile.Steps != i.Steps() {
+		t.Errorf("Steps = %d, want 16 matching the machine's %d", profile.Steps, i.Steps())
+	}
+	for opcode, want := range map[int]int{4: 5, 1: 5, 5: 5, 99: 1} {
+		if got := profile.ByOpcode[opcode]; got != want {
+			t.Errorf("ByOpcode[%d] = %d, want %d", opcode, got, want)
+		}
+	}
+	if got := profile.ByAddress[6]; got != 5 {
+		t.Errorf("ByAddress[6] = %d, want 5", got)
+	}
+	loops := profile.Loops()
+	if len(loops) != 1 || loops[0].From != 6 || loops[0].To != 0 || loops[0].Iterations != 4 || loops[0].Instructions != 15 {
+		t.Errorf("Loops() = %+v, want one loop from 6 back to 0 taken 4 times over 15 instructions", loops)
+	}
+	if spots := profile.Hotspots(); len(spots) != 4 || spots[0].Count != 5 || spots[3].Addr != 9 {
+		t.Errorf("Hotspots() = %+v", spots)
+	}
+
+	var report strings.Builder
+	if err := profile.WriteReport(&report, i, 10); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+	if !strings.Contains(report.String(), "0..6: 4 iterations") {
+		t.Errorf("WriteReport() does not list the loop:\n%s", report.String())
+	}
+
+	var buf bytes.Buffer
+	if err := profile.WritePprof(&buf, i); err != nil {
+		t.Fatalf("WritePprof() error = %v", err)
+	}
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("WritePprof() did not write gzip data: %v", err)
+	}
+	data, err := ioutil.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("WritePprof() wrote corrupt gzip data: %v", err)
+	}
+	if !bytes.Contains(data, []byte("6: JT [10], 0")) {
+		t.Errorf("WritePprof() profile does not name the jump at address 6")
+	}
+}
+
+func TestProfile_WriteReport_FailedRun(t *testing.T) {
+	//the jump to -5 is recorded as a back edge before the next step fails
+	i := Init([]int{1105, 1, -5, 99}, nil, nil)
+	profile := NewProfile()
+	i.SetProfile(profile)
+	if _, err := i.Run(); err == nil {
+		t.Fatalf("Run() error = nil, want the jump to -5 to fail")
+	}
+	var report strings.Builder
+	if err := profile.WriteReport(&report, i, 10); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+	if !strings.Contains(report.String(), "-5..0: 1 iterations") || !strings.Contains(report.String(), "-5:") {
+		t.Errorf("WriteReport() does not list the jump to -5:\n%s", report.String())
+	}
+	if err := profile.WritePprof(&bytes.Buffer{}, i); err != nil {
+		t.Fatalf("WritePprof() error = %v", err)
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package intcode
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/pkg/errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"sort"
+)
+
+//maxScreenPixels is the most pixels an image of a screen may have, and the most characters Render may write. A program
+//drawing tiles far apart would otherwise need an enormous canvas
+const maxScreenPixels = 1 << 26
+
+//Point is a position on a Screen. X grows to the right and Y grows downwards
+type Point struct {
+	X int
+	Y int
+}
+
+//Palette is how each tile id of a Screen is drawn. Tiles missing from a palette are drawn with Unknown and
+//UnknownColor, and cells that were never drawn to are drawn as tile 0
+type Palette struct {
+	Runes        map[int]rune
+	Colors       map[int]color.Color
+	Unknown      rune
+	UnknownColor color.Color
+}
+
+var (
+	//ArcadePalette draws the tiles of the day 13 arcade cabinet: empty, wall, block, paddle and ball
+	ArcadePalette = Palette{
+		Runes: map[int]rune{0: ' ', 1: '#', 2: '=', 3: '-', 4: 'o'},
+		Colors: map[int]color.Color{
+			0: color.Black,
+			1: color.Gray{Y: 0x80},
+			2: color.RGBA{R: 0x40, G: 0x80, B: 0xff, A: 0xff},
+			3: color.White,
+			4: color.RGBA{R: 0xff, G: 0xd0, B: 0x20, A: 0xff},
+		},
+		Unknown:      '?',
+		UnknownColor: color.RGBA{R: 0xff, A: 0xff},
+	}
+	//PaintPalette draws panels painted by the day 11 hull painting robot, black or white
+	PaintPalette = Palette{
+		Runes:        map[int]rune{0: '.', 1: '#'},
+		Colors:       map[int]color.Color{0: color.Black, 1: color.White},
+		Unknown:      '?',
+		UnknownColor: color.RGBA{R: 0xff, A: 0xff},
+	}
+)
+
+//Screen is an OutputSink that draws the (x, y, tile) triples a program outputs onto a sparse canvas. The triple
+//(-1, 0, score) sets the score instead of drawing a tile.
+//
+//Every tile drawn is kept, so the screen can be rendered as an animated GIF. Frames are captured with CaptureFrame, or
+//automatically after every FrameEvery tiles when it isn't 0
+type Screen struct {
+	Palette Palette
+	//Tiles holds the tile last drawn at each point
+	Tiles map[Point]int
+	//Score is the last score output, HasScore is whether one has been output at all
+	Score    int
+	HasScore bool
+	//FrameEvery captures a frame after that many tiles are drawn, 0 to only capture frames with CaptureFrame
+	FrameEvery int
+	//pending holds the start of a triple
+	pending []int
+	//updates is every tile drawn in order, frames is the number of updates shown by each captured frame
+	updates []tileUpdate
+	frames  []int
+}
+
+type tileUpdate struct {
+	Point
+	tile int
+}
+
+//NewScreen creates an empty screen drawn with the passed in palette
+func NewScreen(p Palette) *Screen {
+	return &Screen{Palette: p, Tiles: map[Point]int{}}
+}
+
+//WriteInt takes the next value of a triple, drawing the tile or setting the score once the triple is complete
+func (s *Screen) WriteInt(val int) error {
+	if len(s.pending) < 2 {
+		s.pending = append(s.pending, val)
+		return nil
+	}
+	x, y := s.pending[0], s.pending[1]
+	s.pending = s.pending[:0]
+	if x == -1 && y == 0 {
+		s.Score = val
+		s.HasScore = true
+		return nil
+	}
+	s.Draw(Point{X: x, Y: y}, val)
+	return nil
+}
+
+//Draw sets the tile at p, the same as a program outputting the triple
+func (s *Screen) Draw(p Point, tile int) {
+	s.Tiles[p] = tile
+	s.updates = append(s.updates, tileUpdate{Point: p, tile: tile})
+	if s.FrameEvery > 0 && len(s.updates)%s.FrameEvery == 0 {
+		s.CaptureFrame()
+	}
+}
+
+//CaptureFrame adds the screen as it is now as a frame of the animation written by WriteGIF
+func (s *Screen) CaptureFrame() {
+	if len(s.frames) > 0 && s.frames[len(s.frames)-1] == len(s.updates) {
+		//nothing has been drawn since the last frame
+		return
+	}
+	s.frames = append(s.frames, len(s.updates))
+}
+
+//Frames returns the number of frames captured so far
+func (s *Screen) Frames() int {
+	return len(s.frames)
+}
+
+//Count returns the number of points currently showing tile
+func (s *Screen) Count(tile int) int {
+	count := 0
+	for _, t := range s.Tiles {
+		if t == tile {
+			count++
+		}
+	}
+	return count
+}
+
+//Bounds returns the smallest rectangle holding every point ever drawn to, with Max exclusive like an image.Rectangle.
+//The rectangle is empty when nothing has been drawn
+func (s *Screen) Bounds() image.Rectangle {
+	var r image.Rectangle
+	for idx, u := range s.updates {
+		cell := image.Rect(u.X, u.Y, u.X+1, u.Y+1)
+		if idx == 0 {
+			r = cell
+			continue
+		}
+		r = r.Union(cell)
+	}
+	return r
+}
+
+//Render writes the screen to w as text, one line per row, followed by the score if there is one. It returns an error
+//without writing anything if the screen is too large to draw
+func (s *Screen) Render(w io.Writer) error {
+	r := s.Bounds()
+	if err := checkSize(r, 1); err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			bw.WriteRune(s.Palette.rune(s.Tiles[Point{X: x, Y: y}]))
+		}
+		bw.WriteByte('\n')
+	}
+	if s.HasScore {
+		fmt.Fprintf(bw, "Score: %d\n", s.Score)
+	}
+	return bw.Flush()
+}
+
+//Image draws the screen as it is now, with each point scale pixels wide and high. It returns an error if the image
+//would have more than maxScreenPixels pixels
+func (s *Screen) Image(scale int) (*image.Paletted, error) {
+	r := s.Bounds()
+	if err := checkSize(r, scale); err != nil {
+		return nil, err
+	}
+	colors, index := s.Palette.colors()
+	img := blankImage(r, scale, colors)
+	for p, tile := range s.Tiles {
+		fill(img, r, scale, p, index(tile))
+	}
+	return img, nil
+}
+
+//WritePNG writes the screen as it is now to w as a PNG image, with each point scale pixels wide and high
+func (s *Screen) WritePNG(w io.Writer, scale int) error {
+	img, err := s.Image(scale)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+//WriteGIF writes every captured frame to w as an animated GIF, showing each for delay hundredths of a second. Every
+//frame covers the area of Bounds, so the animation doesn't move as the drawing grows. The screen as it is now is added
+//as the last frame if it hasn't been captured
+func (s *Screen) WriteGIF(w io.Writer, scale int, delay int) error {
+	s.CaptureFrame()
+	r := s.Bounds()
+	if err := checkSize(r, scale); err != nil {
+		return err
+	}
+	colors, index := s.Palette.colors()
+	anim := &gif.GIF{}
+	img := blankImage(r, scale, colors)
+	drawn := 0
+	for _, frame := range s.frames {
+		for ; drawn < frame; drawn++ {
+			u := s.updates[drawn]
+			fill(img, r, scale, u.Point, index(u.tile))
+		}
+		copied := image.NewPaletted(img.Rect, img.Palette)
+		copy(copied.Pix, img.Pix)
+		anim.Image = append(anim.Image, copied)
+		anim.Delay = append(anim.Delay, delay)
+	}
+	return gif.EncodeAll(w, anim)
+}
+
+//checkSize returns an error if the points in r can't be drawn scale pixels wide and high within maxScreenPixels
+func checkSize(r image.Rectangle, scale int) error {
+	if scale < 1 {
+		return errors.New(fmt.Sprintf("invalid scale %d, it must be at least 1", scale))
+	}
+	if r.Empty() {
+		return nil
+	}
+	//the width or height wrap around when points are drawn near opposite ends of the int range
+	width, height := r.Dx(), r.Dy()
+	if width <= 0 || height <= 0 || width > maxScreenPixels/height || scale > maxScreenPixels ||
+		width*height > maxScreenPixels/scale/scale {
+		return errors.New(fmt.Sprintf("the screen spans %v, too large to draw at scale %d", r, scale))
+	}
+	return nil
+}
+
+//blankImage returns an image of the points in r filled with tile 0
+func blankImage(r image.Rectangle, scale int, colors color.Palette) *image.Paletted {
+	//the index of tile 0 is always 0, which is what a new image is filled with
+	return image.NewPaletted(image.Rect(0, 0, r.Dx()*scale, r.Dy()*scale), colors)
+}
+
+//fill colors the pixels of the point p with the palette color at idx, on an image of the points in r
+func fill(img *image.Paletted, r image.Rectangle, scale int, p Point, idx uint8) {
+	x0, y0 := (p.X-r.Min.X)*scale, (p.Y-r.Min.Y)*scale
+	for y := y0; y < y0+scale; y++ {
+		for x := x0; x < x0+scale; x++ {
+			img.SetColorIndex(x, y, idx)
+		}
+	}
+}
+
+func (p Palette) rune(tile int) rune {
+	if r, ok := p.Runes[tile]; ok {
+		return r
+	}
+	return p.Unknown
+}
+
+//colors returns the palette as an image palette, along with the index of each tile in it. Tile 0 is always at index 0
+//and the unknown color is always last
+func (p Palette) colors() (color.Palette, func(tile int) uint8) {
+	tiles := make([]int, 0, len(p.Colors))
+	for tile := range p.Colors {
+		if tile != 0 {
+			tiles = append(tiles, tile)
+		}
+	}
+	sort.Ints(tiles)
+
+	background, ok := p.Colors[0]
+	if !ok {
+		background = color.Black
+	}
+	unknown := p.UnknownColor
+	if unknown == nil {
+		unknown = color.White
+	}
+	colors := color.Palette{background}
+	indexes := map[int]uint8{0: 0}
+	//a GIF palette holds at most 256 colors, tiles past that are drawn as unknown
+	for _, tile := range tiles {
+		if len(colors) == 255 {
+			break
+		}
+		indexes[tile] = uint8(len(colors))
+		colors = append(colors, p.Colors[tile])
+	}
+	colors = append(colors, unknown)
+	unknownIdx := uint8(len(colors) - 1)
+	return colors, func(tile int) uint8 {
+		if idx, ok := indexes[tile]; ok {
+			return idx
+		}
+		return unknownIdx
+	}
+}
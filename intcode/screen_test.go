@@ -0,0 +1,151 @@
+package intcode
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+)
+
+//arcade draws a wall, a block, the ball and a paddle around an empty cell, sets the score, then moves the ball
+var arcade = []int{
+	104, 0, 104, 0, 104, 1,
+	104, 1, 104, 0, 104, 2,
+	104, 0, 104, 1, 104, 4,
+	104, 2, 104, 1, 104, 3,
+	104, -1, 104, 0, 104, 12345,
+	104, 0, 104, 1, 104, 0,
+	104, 1, 104, 1, 104, 4,
+	99,
+}
+
+func TestScreen(t *testing.T) {
+	s := NewScreen(ArcadePalette)
+	if status, err := InitIO(arcade, nil, s).Run(); err != nil || status != Halted {
+		t.Fatalf("Run() = %v, %v, want Halted", status, err)
+	}
+	if !s.HasScore || s.Score != 12345 {
+		t.Errorf("score = %d (set %v), want 12345", s.Score, s.HasScore)
+	}
+	if got := s.Count(4); got != 1 {
+		t.Errorf("Count(4) = %d, want 1", got)
+	}
+	if got, want := s.Bounds(), image.Rect(0, 0, 3, 2); got != want {
+		t.Errorf("Bounds() = %v, want %v", got, want)
+	}
+
+	var text bytes.Buffer
+	if err := s.Render(&text); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if want := "#= \n o-\nScore: 12345\n"; text.String() != want {
+		t.Errorf("Render() = %q, want %q", text.String(), want)
+	}
+
+	var buf bytes.Buffer
+	if err := s.WritePNG(&buf, 2); err != nil {
+		t.Fatalf("WritePNG() error = %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("png.Decode() error = %v", err)
+	}
+	if got := img.Bounds(); got != image.Rect(0, 0, 6, 4) {
+		t.Errorf("PNG bounds = %v, want 6x4", got)
+	}
+	//the ball is at (1, 1), so pixels 2 and 3 in both directions, and (2, 0) was never drawn
+	for _, px := range []struct {
+		x, y int
+		want color.Color
+	}{{3, 3, ArcadePalette.Colors[4]}, {2, 2, ArcadePalette.Colors[4]}, {5, 0, ArcadePalette.Colors[0]}, {0, 0, ArcadePalette.Colors[1]}} {
+		if !sameColor(img.At(px.x, px.y), px.want) {
+			t.Errorf("PNG pixel (%d, %d) = %v, want %v", px.x, px.y, img.At(px.x, px.y), px.want)
+		}
+	}
+}
+
+func TestScreen_WriteGIF(t *testing.T) {
+	s := NewScreen(ArcadePalette)
+	s.FrameEvery = 2
+	s.Draw(Point{X: -1, Y: -1}, 1)
+	s.Draw(Point{X: 0, Y: 0}, 4)
+	s.Draw(Point{X: 0, Y: 0}, 0)
+	s.Draw(Point{X: 1, Y: 1}, 4)
+	s.Draw(Point{X: 1, Y: 0}, 9)
+	var buf bytes.Buffer
+	if err := s.WriteGIF(&buf, 1, 5); err != nil {
+		t.Fatalf("WriteGIF() error = %v", err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("gif.DecodeAll() error = %v", err)
+	}
+	//frames after 2 and 4 tiles, and the last tile added by WriteGIF
+	if len(anim.Image) != 3 {
+		t.Fatalf("GIF has %d frames, want 3", len(anim.Image))
+	}
+	tests := []struct {
+		frame int
+		x, y  int
+		want  color.Color
+	}{
+		{frame: 0, x: 1, y: 1, want: ArcadePalette.Colors[4]},
+		{frame: 1, x: 1, y: 1, want: ArcadePalette.Colors[0]},
+		{frame: 1, x: 2, y: 2, want: ArcadePalette.Colors[4]},
+		{frame: 2, x: 2, y: 1, want: ArcadePalette.UnknownColor},
+		{frame: 2, x: 0, y: 0, want: ArcadePalette.Colors[1]},
+	}
+	for _, tt := range tests {
+		img := anim.Image[tt.frame]
+		if img.Bounds() != image.Rect(0, 0, 3, 3) {
+			t.Errorf("frame %d bounds = %v, want 3x3", tt.frame, img.Bounds())
+		}
+		if got := img.At(tt.x, tt.y); !sameColor(got, tt.want) {
+			t.Errorf("frame %d pixel (%d, %d) = %v, want %v", tt.frame, tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestScreen_TooLarge(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []Point
+		scale  int
+	}{
+		{name: "far apart", points: []Point{{X: 0, Y: 0}, {X: 1 << 40, Y: 1 << 40}}, scale: 1},
+		{name: "opposite ends of the int range", points: []Point{{X: minInt, Y: 0}, {X: maxInt, Y: 0}}, scale: 1},
+		{name: "too large once scaled", points: []Point{{X: 0, Y: 0}, {X: 4095, Y: 4095}}, scale: 4},
+		{name: "zero scale", points: []Point{{X: 0, Y: 0}}, scale: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScreen(ArcadePalette)
+			for _, p := range tt.points {
+				s.Draw(p, 1)
+			}
+			if _, err := s.Image(tt.scale); err == nil {
+				t.Errorf("Image() error = nil, want an error")
+			}
+			if err := s.WritePNG(&bytes.Buffer{}, tt.scale); err == nil {
+				t.Errorf("WritePNG() error = nil, want an error")
+			}
+			if err := s.WriteGIF(&bytes.Buffer{}, tt.scale, 1); err == nil {
+				t.Errorf("WriteGIF() error = nil, want an error")
+			}
+			if tt.scale == 1 {
+				var text bytes.Buffer
+				if err := s.Render(&text); err == nil || text.Len() != 0 {
+					t.Errorf("Render() error = %v after writing %d bytes, want an error and nothing written", err, text.Len())
+				}
+			}
+		})
+	}
+}
+
+func sameColor(a color.Color, b color.Color) bool {
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	return ar == br && ag == bg && ab == bb && aa == ba
+}
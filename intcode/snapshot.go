@@ -0,0 +1,110 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package intcode
+
+import (
+	"encoding/gob"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+)
+
+//Snapshot is the complete state of an Intcode machine at one point in time. The machine's input source, output sink,
+//tracer and profile are not part of its state, they stay with whichever machine a snapshot is restored into
+type Snapshot struct {
+	//Pages holds a copy of every allocated page of memory, keyed by page number
+	Pages        map[int][]int
+	Size         int
+	Pos          int
+	RelativeBase int
+	Halted       bool
+	Steps        int
+	//PendingInput holds values queued with Input that have not been read yet
+	PendingInput []int
+	//Output is the most recent value output by the machine
+	Output int
+}
+
+//Snapshot captures the machine's memory, instruction pointer, relative base and pending input. The snapshot is a copy,
+//running the machine further does not change it
+func (i *Intcode) Snapshot() *Snapshot {
+	return &Snapshot{
+		Pages:        i.memory.copyPages(),
+		Size:         i.memory.size,
+		Pos:          i.pos,
+		RelativeBase: i.relativeBase,
+		Halted:       i.halted,
+		Steps:        i.steps,
+		PendingInput: append([]int(nil), i.pending...),
+		Output:       i.output,
+	}
+}
+
+//Restore puts the machine back into the state captured by the snapshot. The snapshot is copied, so it can be restored
+//any number of times
+func (i *Intcode) Restore(s *Snapshot) {
+	pages := copyPages(s.Pages)
+	owned := make(map[int]bool, len(pages))
+	for num := range pages {
+		owned[num] = true
+	}
+	i.memory = &Memory{pages: pages, owned: owned, size: s.Size}
+	i.pos = s.Pos
+	i.relativeBase = s.RelativeBase
+	i.halted = s.Halted
+	i.steps = s.Steps
+	i.pending = append([]int(nil), s.PendingInput...)
+	i.output = s.Output
+}
+
+//FromSnapshot creates a new machine in the state captured by the snapshot, connected to the passed in input and output
+func FromSnapshot(s *Snapshot, in InputSource, out OutputSink) *Intcode {
+	i := InitIO(nil, in, out)
+	i.Restore(s)
+	return i
+}
+
+//Save writes the snapshot to w in the gob format
+func (s *Snapshot) Save(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(s)
+}
+
+//LoadSnapshot reads a snapshot written by Save
+func LoadSnapshot(r io.Reader) (*Snapshot, error) {
+	var s Snapshot
+	if err := gob.NewDecoder(r).Decode(&s); err != nil {
+		return nil, errors.Wrap(err, "unable to read snapshot")
+	}
+	for num, page := range s.Pages {
+		if len(page) != PageSize || num < 0 {
+			return nil, errors.New("snapshot contains an invalid memory page")
+		}
+	}
+	return &s, nil
+}
+
+//SaveSnapshotFile writes the snapshot to the passed in file, replacing anything already in it
+func SaveSnapshotFile(s *Snapshot, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := s.Save(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+//LoadSnapshotFile reads a snapshot from the passed in file
+func LoadSnapshotFile(filename string) (*Snapshot, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadSnapshot(file)
+}
@@ -0,0 +1,102 @@
+package intcode
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIntcode_SnapshotRestore(t *testing.T) {
+	//doubles every input until it is given a 0, keeping a running total far past the end of the program
+	program := []int{3, 100, 1002, 100, 2, 100, 1, 100, 5000, 5000, 4, 5000, 1005, 100, 0, 99}
+	i := InitIO(program, nil, nil)
+	i.Input(3)
+	if status, err := i.Run(); err != nil || status != HasOutput {
+		t.Fatalf("Run() = %v, %v, want HasOutput", status, err)
+	}
+	i.Input(4, 0)
+	snapshot := i.Snapshot()
+
+	finish := func(m *Intcode) []int {
+		var outputs []int
+		for {
+			status, err := m.Run()
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if status == Halted {
+				return outputs
+			}
+			outputs = append(outputs, m.Output())
+		}
+	}
+	want := finish(i)
+	if !reflect.DeepEqual(want, []int{14, 14}) {
+		t.Fatalf("outputs after the snapshot = %v, want [14 14]", want)
+	}
+
+	//restoring rewinds the machine, as many times as needed
+	for n := 0; n < 2; n++ {
+		i.Restore(snapshot)
+		if got := finish(i); !reflect.DeepEqual(got, want) {
+			t.Errorf("outputs after Restore() = %v, want %v", got, want)
+		}
+	}
+
+	//a snapshot survives a round trip through a file and can start a fresh machine
+	dir, err := ioutil.TempDir("", "intcode")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "machine.snapshot")
+	if err := SaveSnapshotFile(snapshot, path); err != nil {
+		t.Fatalf("SaveSnapshotFile() error = %v", err)
+	}
+	loaded, err := LoadSnapshotFile(path)
+	if err != nil {
+		t.Fatalf("LoadSnapshotFile() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, snapshot) {
+		t.Errorf("LoadSnapshotFile() = %+v, want %+v", loaded, snapshot)
+	}
+	if got := finish(FromSnapshot(loaded, nil, nil)); !reflect.DeepEqual(got, want) {
+		t.Errorf("outputs from FromSnapshot() = %v, want %v", got, want)
+	}
+}
+
+func TestLoadSnapshot_Invalid(t *testing.T) {
+	if _, err := LoadSnapshot(strings.NewReader("not a snapshot")); err == nil {
+		t.Errorf("LoadSnapshot() error = nil, want an error")
+	}
+	var buf bytes.Buffer
+	if err := (&Snapshot{Pages: map[int][]int{0: {1, 2}}}).Save(&buf); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := LoadSnapshot(&buf); err == nil {
+		t.Errorf("LoadSnapshot() of a short page error = nil, want an error")
+	}
+}
+
+func TestDebugger_SaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "intcode")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "session")
+
+	var out strings.Builder
+	d := NewDebugger(InitIO([]int{3, 9, 8, 9, 10, 9, 4, 9, 99, -1, 8}, nil, nil), &out)
+	commands := []string{"input 8", "step", "save " + path, "c", "load " + path, "set 10 9", "c"}
+	if err := d.Repl(strings.NewReader(strings.Join(commands, "\n"))); err != nil {
+		t.Fatalf("Repl() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "output: 1\n") || !strings.Contains(out.String(), "output: 0\n") {
+		t.Errorf("Repl() output does not show the run before and after loading:\n%s", out.String())
+	}
+}
@@ -0,0 +1,500 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package intcode
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+)
+
+//ErrSymbolicControl is returned when a symbolic run reaches an instruction whose behaviour depends on an unknown: a jump
+//or comparison on an unknown value, a write to an unknown address or executing an unknown as an instruction
+var ErrSymbolicControl = errors.New("control flow depends on an unknown")
+
+//errSymbolicSteps is returned when a symbolic run executes more instructions than it was allowed to
+var errSymbolicSteps = errors.New("step limit reached")
+
+//solveStepLimit is the most instructions Solve will execute for a single run of the program, symbolic or not
+const solveStepLimit = 1 << 20
+
+//ExprKind is the kind of a node in an expression tree
+type ExprKind int
+
+const (
+	//ExprConst is a known value
+	ExprConst ExprKind = iota
+	//ExprVar is the unknown value that was initially in a memory cell
+	ExprVar
+	//ExprAdd is the sum of Left and Right
+	ExprAdd
+	//ExprMul is the product of Left and Right
+	ExprMul
+	//ExprLoad is the value read from the address given by Left, which depends on an unknown
+	ExprLoad
+)
+
+//Expr is a node of the expression tree built by symbolic execution. Constants carry their Value, unknowns carry the
+//Addr of the memory cell they were marked on and the other kinds have their operands in Left and Right
+type Expr struct {
+	Kind  ExprKind
+	Value int
+	Addr  int
+	Left  *Expr
+	Right *Expr
+}
+
+//Const returns the value of the expression and whether it is known
+func (e *Expr) Const() (int, bool) {
+	return e.Value, e.Kind == ExprConst
+}
+
+func (e *Expr) String() string {
+	switch e.Kind {
+	case ExprConst:
+		return fmt.Sprintf("%d", e.Value)
+	case ExprVar:
+		return fmt.Sprintf("m[%d]", e.Addr)
+	case ExprAdd:
+		return fmt.Sprintf("(%s + %s)", e.Left, e.Right)
+	case ExprMul:
+		return fmt.Sprintf("(%s * %s)", e.Left, e.Right)
+	default:
+		return fmt.Sprintf("m[%s]", e.Left)
+	}
+}
+
+//Linear rewrites the expression as constant + sum(coefs[addr] * m[addr]). ok is false when the expression is not
+//linear in the unknowns, such as when two unknowns are multiplied together or memory is read from an unknown address
+func (e *Expr) Linear() (coefs map[int]int, constant int, ok bool) {
+	switch e.Kind {
+	case ExprConst:
+		return map[int]int{}, e.Value, true
+	case ExprVar:
+		return map[int]int{e.Addr: 1}, 0, true
+	case ExprAdd:
+		lc, lk, lok := e.Left.Linear()
+		rc, rk, rok := e.Right.Linear()
+		if !lok || !rok {
+			return nil, 0, false
+		}
+		for addr, c := range rc {
+			lc[addr] += c
+			if lc[addr] == 0 {
+				delete(lc, addr)
+			}
+		}
+		return lc, lk + rk, true
+	case ExprMul:
+		lc, lk, lok := e.Left.Linear()
+		rc, rk, rok := e.Right.Linear()
+		if !lok || !rok || (len(lc) > 0 && len(rc) > 0) {
+			return nil, 0, false
+		}
+		//at least one side is a constant, make sure it is the left one and scale the right one by it
+		if len(lc) > 0 {
+			lc, rc = rc, lc
+			lk, rk = rk, lk
+		}
+		scaled := make(map[int]int, len(rc))
+		for addr, c := range rc {
+			if c*lk != 0 {
+				scaled[addr] = c * lk
+			}
+		}
+		return scaled, lk * rk, true
+	default:
+		return nil, 0, false
+	}
+}
+
+func constExpr(val int) *Expr {
+	return &Expr{Kind: ExprConst, Value: val}
+}
+
+//SymbolicMachine runs an Intcode program where some memory cells hold unknowns instead of values. Adds and multiplies
+//build expression trees out of their operands, which can be inspected with Peek and Outputs once the program halts.
+//Anything else that depends on an unknown stops the run with ErrSymbolicControl
+type SymbolicMachine struct {
+	memory       map[int]*Expr
+	pos          int
+	relativeBase int
+	pending      []int
+	outputs      []*Expr
+	steps        int
+}
+
+//NewSymbolic loads program into a symbolic machine and marks each of the unknowns addresses as an unknown
+func NewSymbolic(program []int, unknowns ...int) *SymbolicMachine {
+	s := &SymbolicMachine{memory: make(map[int]*Expr, len(program))}
+	for addr, val := range program {
+		s.memory[addr] = constExpr(val)
+	}
+	for _, addr := range unknowns {
+		s.memory[addr] = &Expr{Kind: ExprVar, Addr: addr}
+	}
+	return s
+}
+
+//Input queues values to be read by input instructions
+func (s *SymbolicMachine) Input(vals ...int) {
+	s.pending = append(s.pending, vals...)
+}
+
+//Peek returns the expression held at addr
+func (s *SymbolicMachine) Peek(addr int) *Expr {
+	if e, ok := s.memory[addr]; ok {
+		return e
+	}
+	return constExpr(0)
+}
+
+//Outputs returns the expressions of every value output so far
+func (s *SymbolicMachine) Outputs() []*Expr {
+	return s.outputs
+}
+
+//Steps returns the number of instructions executed so far
+func (s *SymbolicMachine) Steps() int {
+	return s.steps
+}
+
+//Run executes the program until it halts or fails. maxSteps limits how many instructions are executed, 0 means no limit
+func (s *SymbolicMachine) Run(maxSteps int) error {
+	for {
+		if maxSteps > 0 && s.steps >= maxSteps {
+			return errors.Wrap(errSymbolicSteps, fmt.Sprintf("symbolic run stopped after %d instructions", s.steps))
+		}
+		instruction, ok := s.Peek(s.pos).Const()
+		if !ok {
+			return s.controlError("the instruction is an unknown")
+		}
+		opcode, par1, par2, par3 := decode(instruction)
+		if opcode == 99 {
+			return nil
+		}
+		if _, err := checkInstruction(opcode, [3]int{par1, par2, par3}); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("instruction %d at position %d", instruction, s.pos))
+		}
+		loc1, unknown1 := s.paramLocation(s.pos+1, par1)
+		loc2, unknown2 := s.paramLocation(s.pos+2, par2)
+		loc3, unknown3 := s.paramLocation(s.pos+3, par3)
+
+		switch opcode {
+		case 1, 2:
+			if unknown3 != nil {
+				return s.controlError("the result is written to an unknown address")
+			}
+			a, b := s.load(loc1, unknown1), s.load(loc2, unknown2)
+			var val *Expr
+			var err error
+			if opcode == 1 {
+				val, err = s.add(a, b)
+			} else {
+				val, err = s.mul(a, b)
+			}
+			if err != nil {
+				return err
+			}
+			if err := s.write(loc3, val); err != nil {
+				return err
+			}
+			s.pos += 4
+		case 3:
+			if unknown1 != nil {
+				return s.controlError("the input is written to an unknown address")
+			}
+			if len(s.pending) == 0 {
+				return errors.New(fmt.Sprintf("no input available for the input instruction at position %d", s.pos))
+			}
+			if err := s.write(loc1, constExpr(s.pending[0])); err != nil {
+				return err
+			}
+			s.pending = s.pending[1:]
+			s.pos += 2
+		case 4:
+			s.outputs = append(s.outputs, s.load(loc1, unknown1))
+			s.pos += 2
+		case 5, 6:
+			cond, ok := s.load(loc1, unknown1).Const()
+			if !ok {
+				return s.controlError("the jump condition is an unknown")
+			}
+			target, ok := s.load(loc2, unknown2).Const()
+			if !ok {
+				return s.controlError("the jump target is an unknown")
+			}
+			s.pos += 3
+			if (opcode == 5) == (cond != 0) {
+				s.pos = target
+			}
+		case 7, 8:
+			if unknown3 != nil {
+				return s.controlError("the result is written to an unknown address")
+			}
+			a, aok := s.load(loc1, unknown1).Const()
+			b, bok := s.load(loc2, unknown2).Const()
+			if !aok || !bok {
+				return s.controlError("a compared value is an unknown")
+			}
+			val := 0
+			if (opcode == 7 && a < b) || (opcode == 8 && a == b) {
+				val = 1
+			}
+			if err := s.write(loc3, constExpr(val)); err != nil {
+				return err
+			}
+			s.pos += 4
+		case 9:
+			offset, ok := s.load(loc1, unknown1).Const()
+			if !ok {
+				return s.controlError("the relative base offset is an unknown")
+			}
+			s.relativeBase += offset
+			s.pos += 2
+		}
+		s.steps++
+	}
+}
+
+//paramLocation resolves the memory location of the parameter stored at addr the same way Intcode.paramLocation does.
+//When the location depends on an unknown, the expression for it is returned instead
+func (s *SymbolicMachine) paramLocation(addr int, mode int) (int, *Expr) {
+	if mode == 1 {
+		return addr, nil
+	}
+	param := s.Peek(addr)
+	val, ok := param.Const()
+	switch {
+	case ok && mode == 2:
+		return s.relativeBase + val, nil
+	case ok:
+		return val, nil
+	case mode == 2:
+		return 0, &Expr{Kind: ExprAdd, Left: constExpr(s.relativeBase), Right: param}
+	}
+	return 0, param
+}
+
+//load reads the expression at loc. When the location itself is unknown the value is too, which is only a problem if it
+//ends up being used
+func (s *SymbolicMachine) load(loc int, unknown *Expr) *Expr {
+	if unknown != nil {
+		return &Expr{Kind: ExprLoad, Left: unknown}
+	}
+	return s.Peek(loc)
+}
+
+func (s *SymbolicMachine) write(addr int, val *Expr) error {
+	if addr < 0 {
+		return errors.New(fmt.Sprintf("instruction at position %d writes to negative address %d", s.pos, addr))
+	}
+	s.memory[addr] = val
+	return nil
+}
+
+//add returns the sum of a and b, folding constants together
+func (s *SymbolicMachine) add(a *Expr, b *Expr) (*Expr, error) {
+	av, aok := a.Const()
+	bv, bok := b.Const()
+	switch {
+	case aok && bok:
+		if addOverflows(av, bv) {
+			return nil, &OverflowError{Pos: s.pos, Instruction: s.Peek(s.pos).Value, A: av, B: bv}
+		}
+		return constExpr(av + bv), nil
+	case aok && av == 0:
+		return b, nil
+	case bok && bv == 0:
+		return a, nil
+	}
+	return &Expr{Kind: ExprAdd, Left: a, Right: b}, nil
+}
+
+//mul returns the product of a and b, folding constants together
+func (s *SymbolicMachine) mul(a *Expr, b *Expr) (*Expr, error) {
+	av, aok := a.Const()
+	bv, bok := b.Const()
+	switch {
+	case aok && bok:
+		if mulOverflows(av, bv) {
+			return nil, &OverflowError{Pos: s.pos, Instruction: s.Peek(s.pos).Value, A: av, B: bv}
+		}
+		return constExpr(av * bv), nil
+	case (aok && av == 0) || (bok && bv == 0):
+		return constExpr(0), nil
+	case aok && av == 1:
+		return b, nil
+	case bok && bv == 1:
+		return a, nil
+	}
+	return &Expr{Kind: ExprMul, Left: a, Right: b}, nil
+}
+
+func (s *SymbolicMachine) controlError(reason string) error {
+	return errors.Wrap(ErrSymbolicControl, fmt.Sprintf("instruction at position %d: %s", s.pos, reason))
+}
+
+//Unknown is a memory cell to solve for along with the inclusive range of values it can take
+type Unknown struct {
+	Addr int
+	Min  int
+	Max  int
+}
+
+//Solution is the values found for the unknowns by Solve
+type Solution struct {
+	//Values maps the address of each unknown to the value it must hold
+	Values map[int]int
+	//Expr is the expression that was solved, nil when the program had to be searched instead
+	Expr *Expr
+	//Searched is true when the program could not be solved analytically and every combination was tried instead
+	Searched bool
+}
+
+//Solve finds values for the unknowns that leave target at resultAddr once the program halts. The program is run
+//symbolically first and when the result is linear in the unknowns it is solved directly. When the program branches on
+//an unknown, or the result is not linear, every combination of values is run in turn instead; combinations that make
+//the program fail are skipped. When several combinations work, the one with the smallest values for the earliest
+//unknowns is returned. A nil Solution means no values in range produce the target
+func Solve(program []int, resultAddr int, target int, unknowns []Unknown) (*Solution, error) {
+	addrs := make([]int, len(unknowns))
+	for k, u := range unknowns {
+		if u.Min > u.Max {
+			return nil, errors.New(fmt.Sprintf("unknown at address %d has a min of %d above its max of %d", u.Addr, u.Min, u.Max))
+		}
+		addrs[k] = u.Addr
+	}
+
+	s := NewSymbolic(program, addrs...)
+	err := s.Run(solveStepLimit)
+	if err != nil && errors.Cause(err) != ErrSymbolicControl && errors.Cause(err) != errSymbolicSteps {
+		return nil, err
+	}
+	if err == nil {
+		expr := s.Peek(resultAddr)
+		if coefs, constant, ok := expr.Linear(); ok {
+			values := solveLinear(coefs, target-constant, unknowns)
+			if values == nil {
+				return nil, nil
+			}
+			if check(program, resultAddr, target, values) {
+				return &Solution{Values: values, Expr: expr}, nil
+			}
+			//the concrete machine disagrees, which can only happen when the coefficients overflowed. Trust the
+			//machine and search instead
+		}
+	}
+
+	values, err := searchUnknowns(program, resultAddr, target, unknowns)
+	if err != nil || values == nil {
+		return nil, err
+	}
+	return &Solution{Values: values, Searched: true}, nil
+}
+
+//solveLinear finds values for the unknowns where sum(coefs[addr] * value) == rhs. Every unknown but the last one with
+//a non-zero coefficient is tried in order, the last one is then solved for directly
+func solveLinear(coefs map[int]int, rhs int, unknowns []Unknown) map[int]int {
+	last := -1
+	for k, u := range unknowns {
+		if coefs[u.Addr] != 0 {
+			last = k
+		}
+	}
+	values := make(map[int]int, len(unknowns))
+	for _, u := range unknowns {
+		values[u.Addr] = u.Min
+	}
+	if last == -1 {
+		if rhs == 0 {
+			return values
+		}
+		return nil
+	}
+
+	var try func(k int, rhs int) bool
+	try = func(k int, rhs int) bool {
+		u := unknowns[k]
+		c := coefs[u.Addr]
+		if k == last {
+			if rhs%c != 0 || rhs/c < u.Min || rhs/c > u.Max {
+				return false
+			}
+			values[u.Addr] = rhs / c
+			return true
+		}
+		if c == 0 {
+			return try(k+1, rhs)
+		}
+		for v := u.Min; v <= u.Max; v++ {
+			if try(k+1, rhs-c*v) {
+				values[u.Addr] = v
+				return true
+			}
+		}
+		return false
+	}
+	if !try(0, rhs) {
+		return nil
+	}
+	return values
+}
+
+//searchUnknowns runs the program once for every combination of values of the unknowns, in order, until one leaves
+//target at resultAddr
+func searchUnknowns(program []int, resultAddr int, target int, unknowns []Unknown) (map[int]int, error) {
+	base := Init(program, nil, nil)
+	values := make(map[int]int, len(unknowns))
+
+	var try func(k int) bool
+	try = func(k int) bool {
+		if k == len(unknowns) {
+			return checkFork(base, resultAddr, target, values)
+		}
+		u := unknowns[k]
+		for v := u.Min; v <= u.Max; v++ {
+			values[u.Addr] = v
+			if try(k + 1) {
+				return true
+			}
+		}
+		return false
+	}
+	if !try(0) {
+		return nil, nil
+	}
+	return values, nil
+}
+
+//check runs the program with values poked into memory and reports whether it halts with target at resultAddr
+func check(program []int, resultAddr int, target int, values map[int]int) bool {
+	return checkFork(Init(program, nil, nil), resultAddr, target, values)
+}
+
+//checkFork is check for a fork of an already loaded machine
+func checkFork(base *Intcode, resultAddr int, target int, values map[int]int) bool {
+	machine := base.Fork()
+	for addr, val := range values {
+		machine.Poke(addr, val)
+	}
+	return runToHalt(machine, solveStepLimit) && machine.Peek(resultAddr) == target
+}
+
+//runToHalt runs the machine until it halts, discarding its output. It returns false if the machine fails, needs input
+//or does not halt within maxSteps instructions
+func runToHalt(machine *Intcode, maxSteps int) bool {
+	machine.SetLimits(Limits{MaxSteps: maxSteps})
+	for {
+		//Run pauses at every output, there is nowhere for it to go
+		status, err := machine.Run()
+		switch {
+		case err != nil || status == NeedsInput:
+			return false
+		case status == Halted:
+			return true
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package intcode
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestSymbolic_Linear(t *testing.T) {
+	//m[0] = m[1] * 3 + m[2] + 5. Like the day-2 programs, the unknowns are first used as addresses by an instruction
+	//whose result is never used
+	program := []int{
+		1, 0, 0, 3,
+		1002, 1, 3, 17,
+		1, 17, 2, 17,
+		1001, 17, 5, 0,
+		99, 0,
+	}
+	s := NewSymbolic(program, 1, 2)
+	if err := s.Run(0); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	coefs, constant, ok := s.Peek(0).Linear()
+	if !ok {
+		t.Fatalf("Peek(0) = %s is not linear", s.Peek(0))
+	}
+	if coefs[1] != 3 || coefs[2] != 1 || len(coefs) != 2 || constant != 5 {
+		t.Errorf("Linear() = %v, %d, want map[1:3 2:1], 5", coefs, constant)
+	}
+}
+
+func TestSymbolic_Branch(t *testing.T) {
+	//jumps to 4 if m[1] is not zero
+	s := NewSymbolic([]int{1105, 0, 4, 99, 99}, 1)
+	err := s.Run(0)
+	if errors.Cause(err) != ErrSymbolicControl {
+		t.Errorf("Run() error = %v, want %v", err, ErrSymbolicControl)
+	}
+}
+
+func TestSymbolic_HaltWithModes(t *testing.T) {
+	//a halt written with mode digits still halts, the loop after it is never reached
+	s := NewSymbolic([]int{1101, 1, 2, 0, 199, 1105, 1, 4})
+	if err := s.Run(100); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got, ok := s.Peek(0).Const(); !ok || got != 3 || s.Steps() != 1 {
+		t.Errorf("Peek(0) = %s after %d steps, want 3 after 1", s.Peek(0), s.Steps())
+	}
+}
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		name         string
+		program      []int
+		target       int
+		want         map[int]int
+		wantSearched bool
+	}{
+		{
+			//m[0] = 100 * m[1] + m[2], the same shape as the day-2 program
+			name:    "linear",
+			program: []int{1, 0, 0, 3, 1002, 1, 100, 13, 1, 13, 2, 0, 99, 0},
+			target:  1234,
+			want:    map[int]int{1: 12, 2: 34},
+		},
+		{
+			//m[0] = m[1] * m[2]
+			name:         "product",
+			program:      []int{1, 0, 0, 3, 2, 1, 2, 0, 99},
+			target:       35,
+			want:         map[int]int{1: 1, 2: 35},
+			wantSearched: true,
+		},
+		{
+			//m[0] = 1 when m[1] < m[2], otherwise 0. The first combination to work is 0, 1
+			name:         "branch",
+			program:      []int{1, 0, 0, 3, 7, 1, 2, 0, 99},
+			target:       1,
+			want:         map[int]int{1: 0, 2: 1},
+			wantSearched: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unknowns := []Unknown{{Addr: 1, Min: 0, Max: 99}, {Addr: 2, Min: 0, Max: 99}}
+			got, err := Solve(tt.program, 0, tt.target, unknowns)
+			if err != nil {
+				t.Fatalf("Solve() error = %v", err)
+			}
+			if got == nil {
+				t.Fatalf("Solve() found no solution")
+			}
+			if got.Searched != tt.wantSearched {
+				t.Errorf("Solve() Searched = %v, want %v", got.Searched, tt.wantSearched)
+			}
+			for addr, val := range tt.want {
+				if got.Values[addr] != val {
+					t.Errorf("Solve() Values = %v, want %v", got.Values, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestSolve_NoSolution(t *testing.T) {
+	got, err := Solve([]int{1, 0, 0, 3, 1002, 1, 100, 13, 1, 13, 2, 0, 99, 0}, 0, 1234, []Unknown{{Addr: 1, Min: 0, Max: 9}, {Addr: 2, Min: 0, Max: 9}})
+	if err != nil || got != nil {
+		t.Errorf("Solve() = %v, %v, want no solution", got, err)
+	}
+}
@@ -0,0 +1,457 @@
+// Copyright 2019 Adknown Inc. All rights reserved.
+// Created:  2026-10-18
+// Author:   matt
+// Project:  aoc-2019
+
+package intcode
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"reflect"
+	"strings"
+)
+
+//traceMagic starts every binary trace file, which is how ReadTrace tells them apart from JSON Lines traces
+const traceMagic = "ICTR\x01"
+
+//maxTraceOperands is the most parameters any instruction has, and so the most operands a traced event can hold
+const maxTraceOperands = 3
+
+//TraceEvent records a single executed instruction
+type TraceEvent struct {
+	//Step counts the instructions executed before this one
+	Step int `json:"step"`
+	//IP is the position of the instruction
+	IP int `json:"ip"`
+	//Instruction is the raw instruction value, including its parameter modes
+	Instruction  int `json:"instruction"`
+	RelativeBase int `json:"rb"`
+	//Operands are the raw values of the instruction's parameters
+	Operands []int `json:"operands"`
+	//Values are what each parameter resolved to before the instruction ran: the value read for the parameters the
+	//instruction reads and the address written for the parameter it writes to
+	Values []int         `json:"values"`
+	Writes []MemoryWrite `json:"writes,omitempty"`
+	Input  *int          `json:"input,omitempty"`
+	Output *int          `json:"output,omitempty"`
+}
+
+//MemoryWrite is a single value stored by an instruction
+type MemoryWrite struct {
+	Addr  int `json:"addr"`
+	Value int `json:"value"`
+}
+
+//Opcode returns the opcode of the traced instruction
+func (e TraceEvent) Opcode() int {
+	opcode, _, _, _ := decode(e.Instruction)
+	return opcode
+}
+
+//Modes returns the decoded mode of each of the traced instruction's parameters
+func (e TraceEvent) Modes() []int {
+	_, par1, par2, par3 := decode(e.Instruction)
+	return []int{par1, par2, par3}[:len(e.Operands)]
+}
+
+func (e TraceEvent) String() string {
+	mnemonic, ok := Mnemonics[e.Opcode()]
+	if !ok {
+		mnemonic = fmt.Sprintf("op%d", e.Opcode())
+	}
+	operands := make([]string, len(e.Operands))
+	modes := e.Modes()
+	for idx, val := range e.Operands {
+		operands[idx] = FormatOperand(val, modes[idx])
+		if modes[idx] != 1 {
+			operands[idx] += fmt.Sprintf("=%d", e.Values[idx])
+		}
+	}
+	line := fmt.Sprintf("step %d ip=%d rb=%d %s %s", e.Step, e.IP, e.RelativeBase, mnemonic, strings.Join(operands, ", "))
+	for _, w := range e.Writes {
+		line += fmt.Sprintf(" ; [%d]<-%d", w.Addr, w.Value)
+	}
+	if e.Input != nil {
+		line += fmt.Sprintf(" ; input %d", *e.Input)
+	}
+	if e.Output != nil {
+		line += fmt.Sprintf(" ; output %d", *e.Output)
+	}
+	return line
+}
+
+//Tracer receives an event for every instruction executed by a machine it is attached to
+type Tracer interface {
+	Trace(event TraceEvent) error
+}
+
+//SetTracer attaches a tracer to the machine, or detaches the current one when passed nil
+func (i *Intcode) SetTracer(t Tracer) {
+	i.tracer = t
+}
+
+//startTrace records everything about the instruction at the instruction pointer that is known before it runs
+func (i *Intcode) startTrace() TraceEvent {
+	if i.pos < 0 {
+		//there's no instruction to read, the instruction itself fails with ErrAddressOutOfRange
+		i.writes = i.writes[:0]
+		return TraceEvent{Step: i.steps, IP: i.pos, RelativeBase: i.relativeBase}
+	}
+	instruction := i.memory.Read(i.pos)
+	opcode, par1, par2, par3 := decode(instruction)
+	modes := [3]int{par1, par2, par3}
+	count := paramCount[opcode]
+	event := TraceEvent{
+		Step:         i.steps,
+		IP:           i.pos,
+		Instruction:  instruction,
+		RelativeBase: i.relativeBase,
+		Operands:     make([]int, count),
+		Values:       make([]int, count),
+	}
+	written, isWrite := writeParam[opcode]
+	for p := 0; p < count; p++ {
+		if i.pos+1+p < 0 {
+			//the parameter would be past the highest address
+			break
+		}
+		event.Operands[p] = i.memory.Read(i.pos + 1 + p)
+		loc := i.paramLocation(i.pos+1+p, modes[p])
+		switch {
+		case isWrite && written == p:
+			event.Values[p] = loc
+		case loc >= 0:
+			event.Values[p] = i.memory.Read(loc)
+		}
+	}
+	i.writes = i.writes[:0]
+	return event
+}
+
+//finishTrace adds what the instruction did to its event and passes it on to the tracer
+func (i *Intcode) finishTrace(event TraceEvent, opcode int) error {
+	if len(i.writes) > 0 {
+		event.Writes = append([]MemoryWrite(nil), i.writes...)
+	}
+	switch opcode {
+	case 3:
+		val := event.Writes[0].Value
+		event.Input = &val
+	case 4:
+		val := i.output
+		event.Output = &val
+	}
+	return i.tracer.Trace(event)
+}
+
+//TraceRecorder keeps every traced event in memory
+type TraceRecorder struct {
+	Events []TraceEvent
+}
+
+//Trace records the event
+func (r *TraceRecorder) Trace(event TraceEvent) error {
+	r.Events = append(r.Events, event)
+	return nil
+}
+
+//JSONTracer writes each event to w as a single line of JSON
+func JSONTracer(w io.Writer) Tracer {
+	return &jsonTracer{enc: json.NewEncoder(w)}
+}
+
+type jsonTracer struct {
+	enc *json.Encoder
+}
+
+func (j *jsonTracer) Trace(event TraceEvent) error {
+	return j.enc.Encode(event)
+}
+
+//BinaryTracer writes each event to w in a compact binary format made up of varints. The steps of the events are not
+//stored, they are implied by the order of the events
+func BinaryTracer(w io.Writer) Tracer {
+	return &binaryTracer{w: w}
+}
+
+type binaryTracer struct {
+	w       io.Writer
+	started bool
+	buf     []byte
+}
+
+const (
+	traceHasInput = 1 << iota
+	traceHasOutput
+)
+
+func (b *binaryTracer) Trace(event TraceEvent) error {
+	b.buf = b.buf[:0]
+	if !b.started {
+		b.buf = append(b.buf, traceMagic...)
+		b.started = true
+	}
+	b.putVarint(event.IP, event.Instruction, event.RelativeBase)
+	b.putUvarint(len(event.Operands))
+	b.putVarint(event.Operands...)
+	b.putVarint(event.Values...)
+	b.putUvarint(len(event.Writes))
+	for _, w := range event.Writes {
+		b.putVarint(w.Addr, w.Value)
+	}
+	var flags byte
+	if event.Input != nil {
+		flags |= traceHasInput
+	}
+	if event.Output != nil {
+		flags |= traceHasOutput
+	}
+	b.buf = append(b.buf, flags)
+	if event.Input != nil {
+		b.putVarint(*event.Input)
+	}
+	if event.Output != nil {
+		b.putVarint(*event.Output)
+	}
+	_, err := b.w.Write(b.buf)
+	return err
+}
+
+func (b *binaryTracer) putVarint(vals ...int) {
+	var tmp [binary.MaxVarintLen64]byte
+	for _, val := range vals {
+		n := binary.PutVarint(tmp[:], int64(val))
+		b.buf = append(b.buf, tmp[:n]...)
+	}
+}
+
+func (b *binaryTracer) putUvarint(val int) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], uint64(val))
+	b.buf = append(b.buf, tmp[:n]...)
+}
+
+//TraceReader reads back the events of a trace written by JSONTracer or BinaryTracer
+type TraceReader interface {
+	//Next returns the next event of the trace, or io.EOF once there are none left
+	Next() (TraceEvent, error)
+}
+
+//ReadTrace reads a trace in either of the formats written by JSONTracer and BinaryTracer, telling them apart by the
+//header binary traces start with
+func ReadTrace(r io.Reader) (TraceReader, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(len(traceMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if bytes.Equal(header, []byte(traceMagic)) {
+		if _, err := br.Discard(len(traceMagic)); err != nil {
+			return nil, err
+		}
+		return &binaryTraceReader{r: br}, nil
+	}
+	return &jsonTraceReader{dec: json.NewDecoder(br)}, nil
+}
+
+type jsonTraceReader struct {
+	dec *json.Decoder
+}
+
+func (j *jsonTraceReader) Next() (TraceEvent, error) {
+	var event TraceEvent
+	if err := j.dec.Decode(&event); err != nil {
+		return event, err
+	}
+	return event, checkOperands(len(event.Operands), len(event.Values))
+}
+
+type binaryTraceReader struct {
+	r    *bufio.Reader
+	step int
+}
+
+func (b *binaryTraceReader) Next() (TraceEvent, error) {
+	event := TraceEvent{Step: b.step}
+	ip, err := binary.ReadVarint(b.r)
+	if err != nil {
+		//running out of data right at the start of an event is the normal end of the trace
+		return event, err
+	}
+	event.IP = int(ip)
+	fields, err := b.varints(2)
+	if err != nil {
+		return event, err
+	}
+	event.Instruction, event.RelativeBase = fields[0], fields[1]
+	count, err := binary.ReadUvarint(b.r)
+	if err != nil {
+		return event, truncated(err)
+	}
+	if count > maxTraceOperands {
+		return event, errors.New(fmt.Sprintf("trace event has %d operands, no instruction has more than %d", count,
+			maxTraceOperands))
+	}
+	if event.Operands, err = b.varints(int(count)); err != nil {
+		return event, err
+	}
+	if event.Values, err = b.varints(int(count)); err != nil {
+		return event, err
+	}
+	writes, err := binary.ReadUvarint(b.r)
+	if err != nil {
+		return event, truncated(err)
+	}
+	for w := uint64(0); w < writes; w++ {
+		pair, err := b.varints(2)
+		if err != nil {
+			return event, err
+		}
+		event.Writes = append(event.Writes, MemoryWrite{Addr: pair[0], Value: pair[1]})
+	}
+	flags, err := b.r.ReadByte()
+	if err != nil {
+		return event, truncated(err)
+	}
+	if flags&traceHasInput != 0 {
+		val, err := b.varints(1)
+		if err != nil {
+			return event, err
+		}
+		event.Input = &val[0]
+	}
+	if flags&traceHasOutput != 0 {
+		val, err := b.varints(1)
+		if err != nil {
+			return event, err
+		}
+		event.Output = &val[0]
+	}
+	b.step++
+	return event, nil
+}
+
+func (b *binaryTraceReader) varints(count int) ([]int, error) {
+	vals := make([]int, count)
+	for idx := range vals {
+		val, err := binary.ReadVarint(b.r)
+		if err != nil {
+			return nil, truncated(err)
+		}
+		vals[idx] = int(val)
+	}
+	return vals, nil
+}
+
+//checkOperands returns an error if a traced event's operand and value counts can't come from a real instruction
+func checkOperands(operands int, values int) error {
+	if operands > maxTraceOperands {
+		return errors.New(fmt.Sprintf("trace event has %d operands, no instruction has more than %d", operands,
+			maxTraceOperands))
+	}
+	if values != operands {
+		return errors.New(fmt.Sprintf("trace event has %d operands but %d values", operands, values))
+	}
+	return nil
+}
+
+//truncated turns an EOF part way through an event into an error, as only the end of a whole event is a clean EOF
+func truncated(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+//TraceDivergence describes the first event at which two traces differ
+type TraceDivergence struct {
+	Step int
+	//A and B are the diverging events of each trace. One of them is nil when that trace ended before the other
+	A *TraceEvent
+	B *TraceEvent
+	//Field names what differs between the two events
+	Field string
+}
+
+func (d *TraceDivergence) String() string {
+	describe := func(e *TraceEvent) string {
+		if e == nil {
+			return "<end of trace>"
+		}
+		return e.String()
+	}
+	return fmt.Sprintf("traces diverge at step %d (%s)\n  a: %s\n  b: %s", d.Step, d.Field, describe(d.A), describe(d.B))
+}
+
+//DiffTraces compares two traces event by event and returns where they first diverge, or nil if they are identical
+func DiffTraces(a TraceReader, b TraceReader) (*TraceDivergence, error) {
+	for step := 0; ; step++ {
+		eventA, errA := a.Next()
+		if errA != nil && errA != io.EOF {
+			return nil, errors.Wrap(errA, "unable to read the first trace")
+		}
+		eventB, errB := b.Next()
+		if errB != nil && errB != io.EOF {
+			return nil, errors.Wrap(errB, "unable to read the second trace")
+		}
+		switch {
+		case errA == io.EOF && errB == io.EOF:
+			return nil, nil
+		case errA == io.EOF:
+			return &TraceDivergence{Step: step, B: &eventB, Field: "first trace ended"}, nil
+		case errB == io.EOF:
+			return &TraceDivergence{Step: step, A: &eventA, Field: "second trace ended"}, nil
+		}
+		if field := diffEvents(eventA, eventB); field != "" {
+			return &TraceDivergence{Step: step, A: &eventA, B: &eventB, Field: field}, nil
+		}
+	}
+}
+
+//diffEvents returns the name of the first field that differs between two events, ignoring their steps
+func diffEvents(a TraceEvent, b TraceEvent) string {
+	switch {
+	case a.IP != b.IP:
+		return "ip"
+	case a.Instruction != b.Instruction:
+		return "instruction"
+	case a.RelativeBase != b.RelativeBase:
+		return "relative base"
+	case !equalInts(a.Operands, b.Operands):
+		return "operands"
+	case !equalInts(a.Values, b.Values):
+		return "operand values"
+	case !reflect.DeepEqual(a.Writes, b.Writes) && (len(a.Writes) > 0 || len(b.Writes) > 0):
+		return "memory writes"
+	case !equalOptional(a.Input, b.Input):
+		return "input"
+	case !equalOptional(a.Output, b.Output):
+		return "output"
+	}
+	return ""
+}
+
+func equalInts(a []int, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for idx := range a {
+		if a[idx] != b[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalOptional(a *int, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
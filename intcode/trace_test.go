@@ -0,0 +1,159 @@
+package intcode
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func traceProgram(t *testing.T, program []int, input ...int) []TraceEvent {
+	t.Helper()
+	recorder := &TraceRecorder{}
+	i := InitIO(program, NewSliceInput(input...), &SliceOutput{})
+	i.SetTracer(recorder)
+	if _, err := i.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	return recorder.Events
+}
+
+func TestIntcode_SetTracer(t *testing.T) {
+	events := traceProgram(t, []int{3, 9, 8, 9, 10, 9, 4, 9, 99, -1, 8}, 8)
+	if len(events) != 4 {
+		t.Fatalf("traced %d events, want 4", len(events))
+	}
+	in, eq, out, hlt := events[0], events[1], events[2], events[3]
+	if in.Input == nil || *in.Input != 8 || !reflect.DeepEqual(in.Writes, []MemoryWrite{{Addr: 9, Value: 8}}) {
+		t.Errorf("IN event = %v, want input 8 written to [9]", in)
+	}
+	if eq.IP != 2 || eq.Opcode() != 8 || !reflect.DeepEqual(eq.Modes(), []int{0, 0, 0}) ||
+		!reflect.DeepEqual(eq.Values, []int{8, 8, 9}) {
+		t.Errorf("EQ event = %v, want ip 2 comparing 8 to 8 into [9]", eq)
+	}
+	if out.Output == nil || *out.Output != 1 || out.Step != 2 {
+		t.Errorf("OUT event = %v, want output 1 at step 2", out)
+	}
+	if hlt.Opcode() != 99 || len(hlt.Operands) != 0 {
+		t.Errorf("HLT event = %v", hlt)
+	}
+}
+
+func TestIntcode_SetTracer_OutOfRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []int
+	}{
+		{name: "negative jump", program: []int{1106, 0, -5}},
+		{name: "negative position", program: []int{1, -1, 0, 0, 99}},
+		{name: "negative relative", program: []int{109, -10, 204, 3, 99}},
+		{name: "parameter past the highest address", program: []int{1101, 9, 0, maxInt, 1105, 1, maxInt}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := InitIO(tt.program, nil, nil)
+			i.SetTracer(&TraceRecorder{})
+			if _, err := i.Run(); !errors.Is(err, ErrAddressOutOfRange) {
+				t.Errorf("Run() error = %v, want %v", err, ErrAddressOutOfRange)
+			}
+		})
+	}
+}
+
+func TestReadTrace_RoundTrip(t *testing.T) {
+	events := traceProgram(t, []int{109, 12, 203, 0, 21101, 2, 3, 1, 204, 1, 99, 0, 0, 0}, -7)
+	for _, format := range []string{"json", "binary"} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			tracer := JSONTracer(&buf)
+			if format == "binary" {
+				tracer = BinaryTracer(&buf)
+			}
+			for _, event := range events {
+				if err := tracer.Trace(event); err != nil {
+					t.Fatalf("Trace() error = %v", err)
+				}
+			}
+			reader, err := ReadTrace(&buf)
+			if err != nil {
+				t.Fatalf("ReadTrace() error = %v", err)
+			}
+			for idx, want := range events {
+				got, err := reader.Next()
+				if err != nil {
+					t.Fatalf("Next() error = %v at event %d", err, idx)
+				}
+				if got.String() != want.String() || diffEvents(got, want) != "" {
+					t.Errorf("Next() = %v, want %v", got, want)
+				}
+			}
+			if _, err := reader.Next(); err == nil {
+				t.Errorf("Next() after the last event error = nil, want io.EOF")
+			}
+		})
+	}
+}
+
+func TestReadTrace_InvalidOperands(t *testing.T) {
+	tests := []struct {
+		name  string
+		trace string
+	}{
+		{name: "binary with a huge operand count", trace: "ICTR\x01\x00\x02\x00\xff\xff\xff\xff\xff\xff\xff\xff\x7f"},
+		{name: "binary with 4 operands", trace: "ICTR\x01\x00\x02\x00\x04\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"},
+		{name: "json with 4 operands", trace: `{"ip":0,"instruction":1,"operands":[0,0,0,0],"values":[0,0,0,0]}`},
+		{name: "json with missing values", trace: `{"ip":0,"instruction":1,"operands":[0,0,0],"values":[0]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := ReadTrace(strings.NewReader(tt.trace))
+			if err != nil {
+				t.Fatalf("ReadTrace() error = %v", err)
+			}
+			if event, err := reader.Next(); err == nil {
+				t.Errorf("Next() = %v, want an error", event)
+			}
+		})
+	}
+}
+
+func TestDiffTraces(t *testing.T) {
+	program := []int{3, 9, 8, 9, 10, 9, 4, 9, 99, -1, 8}
+	encode := func(events []TraceEvent) TraceReader {
+		var buf bytes.Buffer
+		tracer := BinaryTracer(&buf)
+		for _, event := range events {
+			if err := tracer.Trace(event); err != nil {
+				t.Fatalf("Trace() error = %v", err)
+			}
+		}
+		reader, err := ReadTrace(&buf)
+		if err != nil {
+			t.Fatalf("ReadTrace() error = %v", err)
+		}
+		return reader
+	}
+
+	same, err := DiffTraces(encode(traceProgram(t, program, 8)), encode(traceProgram(t, program, 8)))
+	if err != nil || same != nil {
+		t.Errorf("DiffTraces() of identical runs = %v, %v, want nil", same, err)
+	}
+
+	diff, err := DiffTraces(encode(traceProgram(t, program, 8)), encode(traceProgram(t, program, 7)))
+	if err != nil {
+		t.Fatalf("DiffTraces() error = %v", err)
+	}
+	if diff == nil || diff.Step != 0 || diff.Field != "memory writes" {
+		t.Errorf("DiffTraces() = %v, want a divergence in the memory written at step 0", diff)
+	}
+
+	events := traceProgram(t, program, 8)
+	short, err := DiffTraces(encode(events), encode(events[:2]))
+	if err != nil {
+		t.Fatalf("DiffTraces() error = %v", err)
+	}
+	if short == nil || short.Step != 2 || short.B != nil || !strings.Contains(short.String(), "<end of trace>") {
+		t.Errorf("DiffTraces() = %v, want the second trace to end at step 2", short)
+	}
+}